@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"example/golang-learn/dtos"
+	"example/golang-learn/services"
+	"net/http"
+	"strconv"
+)
+
+type BlockController struct {
+	ctx          context.Context
+	blockService *services.BlockService
+}
+
+func NewBlockController(ctx context.Context, blockService *services.BlockService) *BlockController {
+	return &BlockController{
+		ctx:          ctx,
+		blockService: blockService,
+	}
+}
+
+func (c *BlockController) AddBlock(w http.ResponseWriter, r *http.Request) {
+	vehicleId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request dtos.AddBlockRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	block, err := c.blockService.AddBlock(vehicleId, request)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(block)
+}
+
+func (c *BlockController) GetBlocks(w http.ResponseWriter, r *http.Request) {
+	vehicleId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	blocks, err := c.blockService.GetBlocks(vehicleId)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(blocks)
+}
+
+func (c *BlockController) RemoveBlock(w http.ResponseWriter, r *http.Request) {
+	vehicleId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	blockId, err := strconv.Atoi(r.PathValue("blockId"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = c.blockService.RemoveBlock(vehicleId, blockId); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -6,18 +6,23 @@ import (
 	"example/golang-learn/dtos"
 	"example/golang-learn/models"
 	"example/golang-learn/services"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type CarparkController struct {
 	ctx            context.Context
 	carparkService *services.CarparkService
+	pricingService *services.PricingService
 }
 
-func NewCarparkController(ctx context.Context, carparkService *services.CarparkService) *CarparkController {
+func NewCarparkController(ctx context.Context, carparkService *services.CarparkService, pricingService *services.PricingService) *CarparkController {
 	return &CarparkController{
 		ctx:            ctx,
 		carparkService: carparkService,
+		pricingService: pricingService,
 	}
 }
 
@@ -30,13 +35,21 @@ func (c *CarparkController) GetCarparks(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	results, err := c.carparkService.GetAvailableVehicles(request.Longitude, request.Latitude, request.Start, request.End)
+	results, radiusKm, err := c.carparkService.GetAvailableVehicles(request)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	err = c.pricingService.PriceCarparks(results, request.Start, request.End)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	// The body stays the plain list of carparks, the radius searched (wider with expand) goes in a header
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Search-Radius-Km", strconv.FormatFloat(radiusKm, 'f', -1, 64))
 	json.NewEncoder(w).Encode(results)
 }
 
@@ -51,10 +64,11 @@ func (c *CarparkController) AddSchedule(w http.ResponseWriter, r *http.Request)
 
 	err = c.carparkService.AddScheduleToVehicle(request)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
+	w.WriteHeader(http.StatusCreated)
 }
 
 func (c *CarparkController) RemoveSchedule(w http.ResponseWriter, r *http.Request) {
@@ -68,7 +82,7 @@ func (c *CarparkController) RemoveSchedule(w http.ResponseWriter, r *http.Reques
 
 	err = c.carparkService.DeleteScheduleFromVehicle(request)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 }
@@ -84,9 +98,32 @@ func (c *CarparkController) AddCarpark(w http.ResponseWriter, r *http.Request) {
 
 	err = c.carparkService.AddCarpark(&request)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
+		return
+	}
+}
+
+func (c *CarparkController) UpdateBuffer(w http.ResponseWriter, r *http.Request) {
+	carparkId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request dtos.UpdateCarparkBufferRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+
+	err = c.carparkService.UpdateBuffer(carparkId, request)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (c *CarparkController) AddVehicle(w http.ResponseWriter, r *http.Request) {
@@ -100,7 +137,7 @@ func (c *CarparkController) AddVehicle(w http.ResponseWriter, r *http.Request) {
 
 	err = c.carparkService.AddVehicleToCarpark(&request)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 }
@@ -120,3 +157,142 @@ func (c *CarparkController) RemoveVehicle(w http.ResponseWriter, r *http.Request
 		return
 	}
 }
+
+// GetCarparkSlots handles GET /carparks/{id}/slots?duration=3h&from=&to=&vehicleId=&buffer=15m&limit=3
+func (c *CarparkController) GetCarparkSlots(w http.ResponseWriter, r *http.Request) {
+	carparkId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	vehicleId := 0
+	if v := r.URL.Query().Get("vehicleId"); v != "" {
+		vehicleId, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	c.findSlots(w, r, carparkId, vehicleId)
+}
+
+// GetVehicleSlots handles GET /vehicles/{id}/slots?duration=3h&from=&to=&buffer=15m&limit=3
+func (c *CarparkController) GetVehicleSlots(w http.ResponseWriter, r *http.Request) {
+	vehicleId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.findSlots(w, r, 0, vehicleId)
+}
+
+func (c *CarparkController) findSlots(w http.ResponseWriter, r *http.Request, carparkId, vehicleId int) {
+	query := r.URL.Query()
+
+	duration, err := time.ParseDuration(query.Get("duration"))
+	if err != nil || duration <= 0 {
+		http.Error(w, "duration must be a positive duration such as 3h", http.StatusBadRequest)
+		return
+	}
+
+	var buffer *time.Duration
+	if v := query.Get("buffer"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			http.Error(w, "buffer must be a duration such as 15m", http.StatusBadRequest)
+			return
+		}
+		buffer = &d
+	}
+
+	from, err := parseTimeQuery(r, "from", time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTimeQuery(r, "to", from.Add(7*24*time.Hour))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !to.After(from) {
+		http.Error(w, "to must be after from", http.StatusBadRequest)
+		return
+	}
+
+	limit := 3
+	if v := query.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	results, err := c.carparkService.FindSlots(dtos.SlotsRequest{
+		CarparkId: carparkId,
+		VehicleId: vehicleId,
+		Duration:  duration,
+		From:      from,
+		To:        to,
+		Buffer:    buffer,
+		Limit:     limit,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// GetVehicleCalendar handles GET /vehicles/{id}/calendar?from=&to=
+func (c *CarparkController) GetVehicleCalendar(w http.ResponseWriter, r *http.Request) {
+	vehicleId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	from, err := parseTimeQuery(r, "from", time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTimeQuery(r, "to", from.Add(7*24*time.Hour))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !to.After(from) {
+		http.Error(w, "to must be after from", http.StatusBadRequest)
+		return
+	}
+
+	calendar, err := c.carparkService.GetVehicleCalendar(vehicleId, from, to)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(calendar)
+}
+
+// parseTimeQuery reads an RFC3339 query parameter, falling back to defaultVal when it is not set
+func parseTimeQuery(r *http.Request, name string, defaultVal time.Time) (time.Time, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return defaultVal, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"example/golang-learn/dtos"
+	"example/golang-learn/services"
+	"net/http"
+	"strconv"
+)
+
+type ClosureController struct {
+	ctx            context.Context
+	closureService *services.ClosureService
+}
+
+func NewClosureController(ctx context.Context, closureService *services.ClosureService) *ClosureController {
+	return &ClosureController{
+		ctx:            ctx,
+		closureService: closureService,
+	}
+}
+
+func (c *ClosureController) AddClosure(w http.ResponseWriter, r *http.Request) {
+	carparkId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request dtos.AddClosureRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response, err := c.closureService.AddClosure(carparkId, request)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *ClosureController) GetClosures(w http.ResponseWriter, r *http.Request) {
+	carparkId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	closures, err := c.closureService.GetClosures(carparkId)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(closures)
+}
+
+func (c *ClosureController) RemoveClosure(w http.ResponseWriter, r *http.Request) {
+	carparkId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	closureId, err := strconv.Atoi(r.PathValue("closureId"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = c.closureService.RemoveClosure(carparkId, closureId); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"example/golang-learn/dtos"
+	"example/golang-learn/services"
+	"net/http"
+	"strconv"
+)
+
+type DiscountController struct {
+	ctx             context.Context
+	discountService *services.DiscountService
+}
+
+func NewDiscountController(ctx context.Context, discountService *services.DiscountService) *DiscountController {
+	return &DiscountController{
+		ctx:             ctx,
+		discountService: discountService,
+	}
+}
+
+func (c *DiscountController) AddDiscount(w http.ResponseWriter, r *http.Request) {
+	vehicleId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request dtos.AddDiscountRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	discount, err := c.discountService.AddDiscount(vehicleId, request)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(discount)
+}
+
+func (c *DiscountController) GetDiscounts(w http.ResponseWriter, r *http.Request) {
+	vehicleId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	discounts, err := c.discountService.GetDiscounts(vehicleId)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(discounts)
+}
+
+func (c *DiscountController) RemoveDiscount(w http.ResponseWriter, r *http.Request) {
+	vehicleId, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	discountId, err := strconv.Atoi(r.PathValue("discountId"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = c.discountService.RemoveDiscount(vehicleId, discountId); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
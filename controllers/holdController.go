@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"example/golang-learn/dtos"
+	"example/golang-learn/services"
+	"net/http"
+	"strconv"
+)
+
+type HoldController struct {
+	ctx            context.Context
+	bookingService *services.BookingService
+}
+
+func NewHoldController(ctx context.Context, bookingService *services.BookingService) *HoldController {
+	return &HoldController{
+		ctx:            ctx,
+		bookingService: bookingService,
+	}
+}
+
+func (c *HoldController) CreateHold(w http.ResponseWriter, r *http.Request) {
+	var request dtos.CreateBookingRequest
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	hold, err := c.bookingService.CreateHold(request)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hold)
+}
+
+func (c *HoldController) ConfirmHold(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	booking, err := c.bookingService.ConfirmHold(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(booking)
+}
+
+func (c *HoldController) ReleaseHold(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err = c.bookingService.ReleaseHold(id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
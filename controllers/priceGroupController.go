@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"example/golang-learn/dtos"
+	"example/golang-learn/services"
+	"net/http"
+	"strconv"
+)
+
+type PriceGroupController struct {
+	ctx               context.Context
+	priceGroupService *services.PriceGroupService
+}
+
+func NewPriceGroupController(ctx context.Context, priceGroupService *services.PriceGroupService) *PriceGroupController {
+	return &PriceGroupController{
+		ctx:               ctx,
+		priceGroupService: priceGroupService,
+	}
+}
+
+func (c *PriceGroupController) CreatePriceGroup(w http.ResponseWriter, r *http.Request) {
+	var request dtos.PriceGroupRequest
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	priceGroup, err := c.priceGroupService.CreatePriceGroup(request)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(priceGroup)
+}
+
+func (c *PriceGroupController) GetPriceGroups(w http.ResponseWriter, r *http.Request) {
+	priceGroups, err := c.priceGroupService.GetPriceGroups()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(priceGroups)
+}
+
+func (c *PriceGroupController) GetPriceGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	priceGroup, err := c.priceGroupService.GetPriceGroup(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(priceGroup)
+}
+
+func (c *PriceGroupController) UpdatePriceGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request dtos.PriceGroupRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	priceGroup, err := c.priceGroupService.UpdatePriceGroup(id, request)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(priceGroup)
+}
+
+func (c *PriceGroupController) DeletePriceGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = c.priceGroupService.DeletePriceGroup(id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *PriceGroupController) ReassignVehicles(w http.ResponseWriter, r *http.Request) {
+	var request dtos.ReassignPriceGroupRequest
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err = c.priceGroupService.ReassignVehicles(request); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
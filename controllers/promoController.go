@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"example/golang-learn/dtos"
+	"example/golang-learn/services"
+	"net/http"
+)
+
+type PromoController struct {
+	ctx          context.Context
+	promoService *services.PromoService
+}
+
+func NewPromoController(ctx context.Context, promoService *services.PromoService) *PromoController {
+	return &PromoController{
+		ctx:          ctx,
+		promoService: promoService,
+	}
+}
+
+func (c *PromoController) CreatePromoCode(w http.ResponseWriter, r *http.Request) {
+	var request dtos.PromoCodeRequest
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	promo, err := c.promoService.CreatePromoCode(request)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(promo)
+}
+
+func (c *PromoController) GetPromoCodes(w http.ResponseWriter, r *http.Request) {
+	promos, err := c.promoService.GetPromoCodes()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(promos)
+}
+
+func (c *PromoController) GetPromoCode(w http.ResponseWriter, r *http.Request) {
+	promo, err := c.promoService.GetPromoCode(r.PathValue("code"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(promo)
+}
+
+func (c *PromoController) DeletePromoCode(w http.ResponseWriter, r *http.Request) {
+	if err := c.promoService.DeletePromoCode(r.PathValue("code")); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"example/golang-learn/dtos"
+	"example/golang-learn/services"
+	"net/http"
+)
+
+type QuoteController struct {
+	ctx            context.Context
+	pricingService *services.PricingService
+}
+
+func NewQuoteController(ctx context.Context, pricingService *services.PricingService) *QuoteController {
+	return &QuoteController{
+		ctx:            ctx,
+		pricingService: pricingService,
+	}
+}
+
+func (c *QuoteController) CreateQuote(w http.ResponseWriter, r *http.Request) {
+	var request dtos.QuoteRequest
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	quote, err := c.pricingService.Quote(request)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	httperrors "example/golang-learn/helpers/errors"
+	apperrors "example/golang-learn/utilities/errors"
+	"net/http"
+)
+
+// writeServiceError maps the service errors to a status code, anything unknown is a 500
+func writeServiceError(w http.ResponseWriter, err error) {
+	var conflict *apperrors.ScheduleConflictError
+	var validation *apperrors.ValidationError
+
+	switch {
+	case errors.As(err, &validation):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(httperrors.NewFieldValidationError(validation.Fields))
+	case errors.As(err, &conflict):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(httperrors.ConflictResponse{
+			Message:   conflict.Error(),
+			BookingId: conflict.BookingId,
+			BlockId:   conflict.BlockId,
+		})
+	case errors.Is(err, apperrors.CarparkNotFound),
+		errors.Is(err, apperrors.VehicleNotFound),
+		errors.Is(err, apperrors.BookingNotFound),
+		errors.Is(err, apperrors.BlockNotFound),
+		errors.Is(err, apperrors.ClosureNotFound),
+		errors.Is(err, apperrors.ScheduleNotFound),
+		errors.Is(err, apperrors.WatchNotFound),
+		errors.Is(err, apperrors.PriceGroupNotFound),
+		errors.Is(err, apperrors.DiscountNotFound),
+		errors.Is(err, apperrors.PromoCodeNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, apperrors.InvalidStatusTransition),
+		errors.Is(err, apperrors.BookingClosed),
+		errors.Is(err, apperrors.CarparkClosed),
+		errors.Is(err, apperrors.HoldExpired),
+		errors.Is(err, apperrors.NoVehicleAvailable),
+		errors.Is(err, apperrors.PriceGroupInUse),
+		errors.Is(err, apperrors.DiscountOverlap),
+		errors.Is(err, apperrors.PromoCodeExhausted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,10 @@
+package dtos
+
+import "time"
+
+type AddBlockRequest struct {
+	Type   string    `json:"type"` // maintenance, inspection or staff
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Reason string    `json:"reason"`
+}
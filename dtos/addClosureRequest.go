@@ -0,0 +1,27 @@
+package dtos
+
+import (
+	"example/golang-learn/models"
+	"time"
+)
+
+type AddClosureRequest struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Reason string    `json:"reason"`
+}
+
+// AddClosureResponse lists the bookings inside the closure so ops can contact those customers.
+// Schedules added directly through POST /schedules have no booking record and are listed separately.
+type AddClosureResponse struct {
+	Closure            models.Closure      `json:"closure"`
+	CollidingBookings  []models.Booking    `json:"collidingBookings"`
+	CollidingSchedules []CollidingSchedule `json:"collidingSchedules"`
+}
+
+type CollidingSchedule struct {
+	VehicleId int       `json:"vehicleId"`
+	BookingId int       `json:"bookingId"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+}
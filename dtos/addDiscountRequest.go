@@ -0,0 +1,9 @@
+package dtos
+
+import "time"
+
+type AddDiscountRequest struct {
+	Percent float64   `json:"percent"` // off the price group's time charge
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+}
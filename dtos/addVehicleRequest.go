@@ -3,15 +3,15 @@ package dtos
 import "example/golang-learn/models"
 
 type AddVehicleRequest struct {
-	CarparkName    string       `json:"carparkName"`
-	MakeName       string       `json:"makeName"`
-	ModelName      string       `json:"modelName"`
-	PlateNumber    string       `json:"plateNumber"`
-	Seats          int          `json:"seats"`
-	PriceGroupName string       `json:"priceGroupName"`
-	PriceGroupId   int          `json:"priceGroupId"`
-	Images         []string     `json:"images"`
-	Lots           []models.Lot `json:"lots"`
+	CarparkName   string       `json:"carparkName"`
+	MakeName      string       `json:"makeName"`
+	ModelName     string       `json:"modelName"`
+	PlateNumber   string       `json:"plateNumber"`
+	Seats         int          `json:"seats"`
+	VehicleTypeId int          `json:"vehicleTypeId"`
+	PriceGroupId  int          `json:"priceGroupId"` // the name is taken from the price group
+	Images        []string     `json:"images"`
+	Lots          []models.Lot `json:"lots"`
 }
 
 type RemoveVehicleRequest struct {
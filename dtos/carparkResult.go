@@ -0,0 +1,35 @@
+package dtos
+
+import "example/golang-learn/models"
+
+// AvailableVehicle is the trimmed view of a vehicle returned by the carpark search,
+// schedules are left out so other customers' bookings are never exposed.
+// JSON keys keep the Go field names GET /carparks has always returned.
+type AvailableVehicle struct {
+	Id             int              `bson:"_id"`
+	MakeName       string           `bson:"makeName"`
+	ModelName      string           `bson:"modelName"`
+	Seats          int              `bson:"seats"`
+	VehicleTypeId  int              `bson:"vehicleTypeId"`
+	PriceGroupId   int              `bson:"priceGroupId"`
+	PriceGroupName string           `bson:"priceGroupName"`
+	Lots           []models.Lot     `bson:"lots"`
+	Images         []string         `bson:"images"`
+	Slashed        *models.Discount `bson:"slashed,omitempty" json:",omitempty"` // discount covering the searched window
+	QuotedTotal    *float64         `bson:"-" json:",omitempty"`                 // price of the searched window, see PricingService
+	OriginalTotal  *float64         `bson:"-" json:",omitempty"`                 // QuotedTotal before the slashed discount
+}
+
+type CarparkResult struct {
+	Id                int                  `bson:"_id"`
+	Name              string               `bson:"name"`
+	PostalCode        string               `bson:"postalCode"`
+	Address           string               `bson:"address"`
+	Location          models.Location      `bson:"location"`
+	OpeningHours      *models.OpeningTimes `bson:"openingHours" json:",omitempty"`
+	Distance          float64              `bson:"dist" json:"distance"`
+	AvailableVehicles int                  `bson:"availableVehicles"`
+	HasSlashedVehicle bool                 `bson:"hasSlashedVehicle"`
+	TotalVehicles     int                  `bson:"totalVehicles"`
+	Vehicles          []AvailableVehicle   `bson:"vehicles"`
+}
@@ -8,6 +8,10 @@ type CarparksRequest struct {
 	PriceGroupIds  []int     `json:"priceGroupIds"`
 	VehicleTypeIds []int     `json:"vehicleTypeIds"`
 	NumSeats       int       `json:"numSeats"`
+	RadiusKm       float64   `json:"radiusKm" validate:"gte=0"`
+	Expand         bool      `json:"expand"` // widen the radius until a vehicle is available
+	SlashedOnly    bool      `json:"slashedOnly"`
+	SortBy         string    `json:"sortBy" validate:"omitempty,oneof=distance slashed"` // "distance" (default) or "slashed" to list carparks with discounts first
 	Start          time.Time `json:"start" validate:"required"`
 	End            time.Time `json:"end" validate:"required"`
 }
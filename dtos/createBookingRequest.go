@@ -0,0 +1,12 @@
+package dtos
+
+import "time"
+
+type CreateBookingRequest struct {
+	UserId    int       `json:"userId"`
+	CarparkId int       `json:"carparkId"`
+	VehicleId int       `json:"vehicleId"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	PromoCode string    `json:"promoCode"`
+}
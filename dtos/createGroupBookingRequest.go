@@ -0,0 +1,24 @@
+package dtos
+
+import (
+	"example/golang-learn/models"
+	"time"
+)
+
+type GroupBookingVehicle struct {
+	CarparkId int `json:"carparkId"`
+	VehicleId int `json:"vehicleId"`
+}
+
+// CreateGroupBookingRequest books every listed vehicle for the same window, all or nothing
+type CreateGroupBookingRequest struct {
+	UserId   int                   `json:"userId"`
+	Start    time.Time             `json:"start"`
+	End      time.Time             `json:"end"`
+	Vehicles []GroupBookingVehicle `json:"vehicles"`
+}
+
+type GroupBookingResponse struct {
+	GroupId  int              `json:"groupId"`
+	Bookings []models.Booking `json:"bookings"`
+}
@@ -0,0 +1,15 @@
+package dtos
+
+import "time"
+
+// CreatePoolBookingRequest books any vehicle at the carpark that matches the criteria
+type CreatePoolBookingRequest struct {
+	UserId         int       `json:"userId"`
+	CarparkId      int       `json:"carparkId"`
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"`
+	PriceGroupIds  []int     `json:"priceGroupIds"`
+	VehicleTypeIds []int     `json:"vehicleTypeIds"`
+	NumSeats       int       `json:"numSeats"`
+	PromoCode      string    `json:"promoCode"`
+}
@@ -0,0 +1,36 @@
+package dtos
+
+import (
+	"example/golang-learn/models"
+	"time"
+)
+
+// CreateRecurringBookingRequest follows RRULE: Start/End is the first occurrence,
+// it repeats every Interval days or weeks on ByDay until Until or Count occurrences
+type CreateRecurringBookingRequest struct {
+	UserId    int        `json:"userId"`
+	CarparkId int        `json:"carparkId"`
+	VehicleId int        `json:"vehicleId"`
+	Start     time.Time  `json:"start"`
+	End       time.Time  `json:"end"`
+	Frequency string     `json:"frequency"` // daily or weekly
+	Interval  int        `json:"interval"`  // defaults to 1
+	ByDay     []string   `json:"byDay"`     // MO, TU, WE, TH, FR, SA, SU
+	Until     *time.Time `json:"until"`
+	Count     int        `json:"count"`
+}
+
+type OccurrenceConflict struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Reason    string    `json:"reason"`
+	BookingId int       `json:"bookingId,omitempty"`
+	BlockId   int       `json:"blockId,omitempty"`
+}
+
+// RecurringBookingResponse has either the created bookings, or the conflicts when nothing was booked
+type RecurringBookingResponse struct {
+	SeriesId  int                  `json:"seriesId,omitempty"`
+	Bookings  []models.Booking     `json:"bookings"`
+	Conflicts []OccurrenceConflict `json:"conflicts"`
+}
@@ -0,0 +1,17 @@
+package dtos
+
+import "time"
+
+// CreateWatchRequest needs either CarparkId, or Longitude/Latitude with RadiusKm
+type CreateWatchRequest struct {
+	UserId         int       `json:"userId"`
+	CarparkId      int       `json:"carparkId"`
+	Longitude      float64   `json:"longitude"`
+	Latitude       float64   `json:"latitude"`
+	RadiusKm       float64   `json:"radiusKm"`
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"`
+	PriceGroupIds  []int     `json:"priceGroupIds"`
+	VehicleTypeIds []int     `json:"vehicleTypeIds"`
+	NumSeats       int       `json:"numSeats"`
+}
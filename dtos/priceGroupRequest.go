@@ -0,0 +1,20 @@
+package dtos
+
+import "example/golang-learn/models"
+
+type PriceGroupRequest struct {
+	Name              string            `json:"name"`
+	HourlyRate        float64           `json:"hourlyRate"`
+	WeekendHourlyRate float64           `json:"weekendHourlyRate"`
+	DailyCap          float64           `json:"dailyCap"`
+	PerKmRate         float64           `json:"perKmRate"`
+	PeakBands         []models.PeakBand `json:"peakBands"`
+}
+
+// ReassignPriceGroupRequest moves the listed vehicles, or every vehicle of FromPriceGroupId, to ToPriceGroupId.
+// When both are given only the listed vehicles that are in FromPriceGroupId move.
+type ReassignPriceGroupRequest struct {
+	FromPriceGroupId int   `json:"fromPriceGroupId"`
+	ToPriceGroupId   int   `json:"toPriceGroupId"`
+	VehicleIds       []int `json:"vehicleIds"`
+}
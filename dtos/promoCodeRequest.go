@@ -0,0 +1,16 @@
+package dtos
+
+import "time"
+
+type PromoCodeRequest struct {
+	Code              string    `json:"code"`
+	Type              string    `json:"type"` // percentage or fixed
+	Value             float64   `json:"value"`
+	ValidFrom         time.Time `json:"validFrom"`
+	ValidTo           time.Time `json:"validTo"`
+	MaxRedemptions    int       `json:"maxRedemptions"`
+	MaxPerUser        int       `json:"maxPerUser"`
+	MinBookingMinutes int       `json:"minBookingMinutes"`
+	PriceGroupIds     []int     `json:"priceGroupIds"`
+	CarparkIds        []int     `json:"carparkIds"`
+}
@@ -0,0 +1,27 @@
+package dtos
+
+import "time"
+
+type QuoteRequest struct {
+	UserId      int       `json:"userId"` // needed for the per-user limit of a promo code
+	VehicleId   int       `json:"vehicleId"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	EstimatedKm float64   `json:"estimatedKm"`
+	PromoCode   string    `json:"promoCode"`
+}
+
+type Quote struct {
+	VehicleId      int       `json:"vehicleId"`
+	PriceGroupId   int       `json:"priceGroupId"`
+	PriceGroupName string    `json:"priceGroupName"`
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"`
+	TimeCharge     float64   `json:"timeCharge"`
+	DistanceCharge float64   `json:"distanceCharge"`
+	Total          float64   `json:"total"`
+	DiscountId     int       `json:"discountId,omitempty"`    // the vehicle discount applied to the time charge
+	OriginalTotal  float64   `json:"originalTotal,omitempty"` // the total before the vehicle discount
+	PromoCode      string    `json:"promoCode,omitempty"`
+	PromoDiscount  float64   `json:"promoDiscount,omitempty"`
+}
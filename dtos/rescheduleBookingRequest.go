@@ -0,0 +1,8 @@
+package dtos
+
+import "time"
+
+type RescheduleBookingRequest struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
@@ -0,0 +1,33 @@
+package dtos
+
+import "time"
+
+type SlotsRequest struct {
+	CarparkId int
+	VehicleId int // optional when CarparkId is set, limits the search to one vehicle
+	Duration  time.Duration
+	From      time.Time
+	To        time.Time
+	Buffer    *time.Duration // gap kept free before and after every existing schedule, nil uses the configured buffer
+	Limit     int            // max windows per vehicle
+}
+
+type TimeWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type VehicleSlots struct {
+	VehicleId   int          `json:"vehicleId"`
+	PlateNumber string       `json:"plateNumber"`
+	Windows     []TimeWindow `json:"windows"`
+}
+
+type VehicleCalendar struct {
+	VehicleId int          `json:"vehicleId"`
+	From      time.Time    `json:"from"`
+	To        time.Time    `json:"to"`
+	Busy      []TimeWindow `json:"busy"`
+	Free      []TimeWindow `json:"free"`
+	Closed    []TimeWindow `json:"closed"` // outside the opening hours, nothing can be picked up or returned
+}
@@ -0,0 +1,5 @@
+package dtos
+
+type UpdateBookingStatusRequest struct {
+	Status string `json:"status"`
+}
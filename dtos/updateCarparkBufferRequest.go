@@ -0,0 +1,7 @@
+package dtos
+
+// UpdateCarparkBufferRequest sets the carpark's turnaround buffer, null removes the override
+// so the global BufferMinutes setting applies again
+type UpdateCarparkBufferRequest struct {
+	BufferMinutes *int `json:"bufferMinutes"`
+}
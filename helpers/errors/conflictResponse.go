@@ -0,0 +1,7 @@
+package errors
+
+type ConflictResponse struct {
+	Message   string `json:"message"`
+	BookingId int    `json:"bookingId,omitempty"`
+	BlockId   int    `json:"blockId,omitempty"`
+}
@@ -40,3 +40,10 @@ func NewValidationError(errs z.ZogIssueList) *ErrorResponse {
 	}
 	return errorResponse
 }
+
+func NewFieldValidationError(fields map[string][]string) *ErrorResponse {
+	return &ErrorResponse{
+		Message: http.StatusUnprocessableEntity,
+		Errors:  fields,
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/rs/zerolog"
@@ -37,6 +38,7 @@ func main() {
 
 	v := viper.New()
 	v.SetConfigFile(".env")
+	v.SetDefault("NOTIFIER_FILE", "notifications.log")
 	err := v.ReadInConfig()
 	if err != nil {
 		panic(fmt.Errorf("could not read config: %w", err))
@@ -66,6 +68,12 @@ func main() {
 	database := client.Database("getgo")
 	collection := database.Collection("carparks")
 	settingCollection := database.Collection("settings")
+	bookingCollection := database.Collection("bookings")
+	counterCollection := database.Collection("counters")
+	watchCollection := database.Collection("watches")
+	priceGroupCollection := database.Collection("price_groups")
+	promoCodeCollection := database.Collection("promo_codes")
+	promoRedemptionCollection := database.Collection("promo_redemptions")
 
 	env := v.GetString("ENVIRONMENT")
 	fmt.Println("started environment: ", env)
@@ -75,15 +83,39 @@ func main() {
 	//	Str("foo", "bar").
 	//	Msg("")
 
-	carparkService := services.NewCarparkService(collection)
 	settingService := services.NewSettingService(settingCollection)
-	_ = settingService.Set("RadiusKm", "20")
-	radius, _ := settingService.GetInt("RadiusKm", 20)
+	radius, _ := settingService.GetInt(services.SettingRadiusKm, 20)
 	fmt.Printf("RadiusKm: %v\n", radius)
+	carparkService := services.NewCarparkService(collection, priceGroupCollection, settingService)
+
+	promoService := services.NewPromoService(promoCodeCollection, promoRedemptionCollection)
+	pricingService := services.NewPricingService(priceGroupCollection, carparkService, promoService)
+	bookingService := services.NewBookingService(client, bookingCollection, counterCollection, carparkService, settingService, pricingService, promoService)
+	blockService := services.NewBlockService(counterCollection, carparkService)
+	closureService := services.NewClosureService(collection, counterCollection, carparkService, bookingService)
+
+	go bookingService.SweepExpiredHolds(ctx, time.Minute)
+
+	var notifier services.Notifier = services.NewLogNotifier()
+	if v.GetString("NOTIFIER") == "file" {
+		notifier = services.NewFileNotifier(v.GetString("NOTIFIER_FILE"))
+	}
+	watchService := services.NewWatchService(watchCollection, counterCollection, carparkService, notifier)
+	priceGroupService := services.NewPriceGroupService(client, priceGroupCollection, counterCollection, collection)
+	discountService := services.NewDiscountService(collection, counterCollection, carparkService)
 
 	userService := services.NewUserService(ctx)
 	userController := controllers.NewUserController(ctx, userService)
-	carparkController := controllers.NewCarparkController(ctx, carparkService)
+	carparkController := controllers.NewCarparkController(ctx, carparkService, pricingService)
+	bookingController := controllers.NewBookingController(ctx, bookingService)
+	blockController := controllers.NewBlockController(ctx, blockService)
+	closureController := controllers.NewClosureController(ctx, closureService)
+	holdController := controllers.NewHoldController(ctx, bookingService)
+	watchController := controllers.NewWatchController(ctx, watchService)
+	quoteController := controllers.NewQuoteController(ctx, pricingService)
+	priceGroupController := controllers.NewPriceGroupController(ctx, priceGroupService)
+	discountController := controllers.NewDiscountController(ctx, discountService)
+	promoController := controllers.NewPromoController(ctx, promoService)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", handleRoot)
@@ -91,14 +123,62 @@ func main() {
 	mux.HandleFunc("POST /users", userController.CreateUser)
 	mux.HandleFunc("GET /users/{id}", userController.GetUser)
 	mux.HandleFunc("DELETE /users/{id}", userController.DeleteUser)
+	mux.HandleFunc("GET /users/{id}/bookings", bookingController.GetUserBookings)
 
 	mux.HandleFunc("GET /carparks", carparkController.GetCarparks)
 	mux.HandleFunc("POST /carparks", carparkController.AddCarpark)
+	mux.HandleFunc("GET /carparks/{id}/slots", carparkController.GetCarparkSlots)
+	mux.HandleFunc("PUT /carparks/{id}/buffer", carparkController.UpdateBuffer)
+	mux.HandleFunc("POST /carparks/{id}/closures", closureController.AddClosure)
+	mux.HandleFunc("GET /carparks/{id}/closures", closureController.GetClosures)
+	mux.HandleFunc("DELETE /carparks/{id}/closures/{closureId}", closureController.RemoveClosure)
 	mux.HandleFunc("POST /vehicles", carparkController.AddVehicle)
 	mux.HandleFunc("DELETE /vehicles", carparkController.RemoveVehicle)
+	mux.HandleFunc("GET /vehicles/{id}/slots", carparkController.GetVehicleSlots)
+	mux.HandleFunc("GET /vehicles/{id}/calendar", carparkController.GetVehicleCalendar)
+	mux.HandleFunc("POST /vehicles/{id}/blocks", blockController.AddBlock)
+	mux.HandleFunc("GET /vehicles/{id}/blocks", blockController.GetBlocks)
+	mux.HandleFunc("DELETE /vehicles/{id}/blocks/{blockId}", blockController.RemoveBlock)
+	mux.HandleFunc("POST /vehicles/{id}/discounts", discountController.AddDiscount)
+	mux.HandleFunc("GET /vehicles/{id}/discounts", discountController.GetDiscounts)
+	mux.HandleFunc("DELETE /vehicles/{id}/discounts/{discountId}", discountController.RemoveDiscount)
 	mux.HandleFunc("POST /schedules", carparkController.AddSchedule)
 	mux.HandleFunc("DELETE /schedules", carparkController.RemoveSchedule)
 
+	mux.HandleFunc("POST /bookings", bookingController.CreateBooking)
+	mux.HandleFunc("POST /bookings/pool", bookingController.CreatePoolBooking)
+	mux.HandleFunc("GET /bookings/{id}", bookingController.GetBooking)
+	mux.HandleFunc("PATCH /bookings/{id}", bookingController.RescheduleBooking)
+	mux.HandleFunc("POST /bookings/{id}/status", bookingController.UpdateBookingStatus)
+	mux.HandleFunc("POST /bookings/series", bookingController.CreateRecurringBooking)
+	mux.HandleFunc("POST /bookings/series/{seriesId}/cancel", bookingController.CancelSeries)
+	mux.HandleFunc("POST /bookings/groups", bookingController.CreateGroupBooking)
+	mux.HandleFunc("POST /bookings/groups/{groupId}/cancel", bookingController.CancelGroup)
+	mux.HandleFunc("GET /cancellation-policy", bookingController.GetCancellationPolicy)
+	mux.HandleFunc("PUT /cancellation-policy", bookingController.SetCancellationPolicy)
+
+	mux.HandleFunc("POST /holds", holdController.CreateHold)
+	mux.HandleFunc("POST /holds/{id}/confirm", holdController.ConfirmHold)
+	mux.HandleFunc("DELETE /holds/{id}", holdController.ReleaseHold)
+
+	mux.HandleFunc("POST /watches", watchController.CreateWatch)
+	mux.HandleFunc("GET /watches/{id}", watchController.GetWatch)
+	mux.HandleFunc("DELETE /watches/{id}", watchController.DeleteWatch)
+
+	mux.HandleFunc("POST /quotes", quoteController.CreateQuote)
+
+	mux.HandleFunc("POST /price-groups", priceGroupController.CreatePriceGroup)
+	mux.HandleFunc("GET /price-groups", priceGroupController.GetPriceGroups)
+	mux.HandleFunc("GET /price-groups/{id}", priceGroupController.GetPriceGroup)
+	mux.HandleFunc("PUT /price-groups/{id}", priceGroupController.UpdatePriceGroup)
+	mux.HandleFunc("DELETE /price-groups/{id}", priceGroupController.DeletePriceGroup)
+	mux.HandleFunc("POST /price-groups/reassign", priceGroupController.ReassignVehicles)
+
+	mux.HandleFunc("POST /promo-codes", promoController.CreatePromoCode)
+	mux.HandleFunc("GET /promo-codes", promoController.GetPromoCodes)
+	mux.HandleFunc("GET /promo-codes/{code}", promoController.GetPromoCode)
+	mux.HandleFunc("DELETE /promo-codes/{code}", promoController.DeletePromoCode)
+
 	fmt.Println("Server listening to :8081")
 	http.ListenAndServe(":8081", mux)
 }
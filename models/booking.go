@@ -0,0 +1,58 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	BookingStatusHeld      = "held" // a temporary hold during checkout, expires unless confirmed
+	BookingStatusReserved  = "reserved"
+	BookingStatusActive    = "active"
+	BookingStatusCompleted = "completed"
+	BookingStatusCancelled = "cancelled"
+	BookingStatusNoShow    = "no_show"
+	BookingStatusExpired   = "expired"
+)
+
+// TerminalBookingStatuses no longer hold the vehicle, every other status blocks availability
+var TerminalBookingStatuses = []string{BookingStatusCompleted, BookingStatusCancelled, BookingStatusNoShow, BookingStatusExpired}
+
+// held -> reserved is not listed, holds are confirmed through ConfirmHold which also checks the expiry
+var bookingTransitions = map[string][]string{
+	BookingStatusHeld:     {BookingStatusCancelled, BookingStatusExpired},
+	BookingStatusReserved: {BookingStatusActive, BookingStatusCancelled, BookingStatusNoShow},
+	BookingStatusActive:   {BookingStatusCompleted},
+}
+
+func CanTransitionBooking(from, to string) bool {
+	return slices.Contains(bookingTransitions[from], to)
+}
+
+func IsTerminalBookingStatus(status string) bool {
+	return slices.Contains(TerminalBookingStatuses, status)
+}
+
+type BookingStatusChange struct {
+	Status string    `bson:"status" json:"status"`
+	At     time.Time `bson:"at" json:"at"`
+}
+
+type Booking struct {
+	Id            int                   `bson:"_id" json:"id"`
+	UserId        int                   `bson:"userId" json:"userId"`
+	CarparkId     int                   `bson:"carparkId" json:"carparkId"`
+	VehicleId     int                   `bson:"vehicleId" json:"vehicleId"`
+	SeriesId      int                   `bson:"seriesId,omitempty" json:"seriesId,omitempty"` // set on bookings created from a recurring rule
+	GroupId       int                   `bson:"groupId,omitempty" json:"groupId,omitempty"`   // set on bookings created together as a group
+	Start         time.Time             `bson:"start" json:"start"`
+	End           time.Time             `bson:"end" json:"end"`
+	Status        string                `bson:"status" json:"status"`
+	StatusHistory []BookingStatusChange `bson:"statusHistory" json:"statusHistory"`
+	ExpiresAt     *time.Time            `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"` // only set while held
+	PromoCode     string                `bson:"promoCode,omitempty" json:"promoCode,omitempty"`
+	PromoDiscount float64               `bson:"promoDiscount,omitempty" json:"promoDiscount,omitempty"`
+	Price         *float64              `bson:"price,omitempty" json:"price,omitempty"` // agreed when booked, after discounts and promo code
+	Fee           *BookingFee           `bson:"fee,omitempty" json:"fee,omitempty"`     // late cancellation or no-show fee for billing
+	CreatedAt     time.Time             `bson:"createdAt" json:"createdAt"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+const (
+	FeeReasonCancellation = "cancellation"
+	FeeReasonNoShow       = "no_show"
+)
+
+// CancellationPolicy is stored on the settings document. Cancelling FreeHours or more before the start is free,
+// later cancellations pay the fee of the first tier whose MinHoursBefore has not passed yet.
+type CancellationPolicy struct {
+	FreeHours float64            `bson:"freeHours" json:"freeHours"`
+	Tiers     []CancellationTier `bson:"tiers" json:"tiers"`
+	NoShow    CancellationTier   `bson:"noShow" json:"noShow"` // MinHoursBefore is not used
+}
+
+// CancellationTier charges a percentage of the booking price plus a fixed amount
+type CancellationTier struct {
+	MinHoursBefore float64 `bson:"minHoursBefore" json:"minHoursBefore"`
+	FeePercent     float64 `bson:"feePercent" json:"feePercent"`
+	FeeFixed       float64 `bson:"feeFixed" json:"feeFixed"`
+}
+
+// DefaultCancellationPolicy applies until a policy is saved in the settings
+var DefaultCancellationPolicy = CancellationPolicy{
+	FreeHours: 24,
+	Tiers: []CancellationTier{
+		{MinHoursBefore: 2, FeePercent: 25},
+		{MinHoursBefore: 0, FeePercent: 50},
+	},
+	NoShow: CancellationTier{FeePercent: 100},
+}
+
+// Clone copies the policy without sharing its tiers
+func (p CancellationPolicy) Clone() CancellationPolicy {
+	p.Tiers = slices.Clone(p.Tiers)
+	return p
+}
+
+// BookingFee is what the customer is billed for cancelling late or not showing up
+type BookingFee struct {
+	Amount     float64   `bson:"amount" json:"amount"`
+	Reason     string    `bson:"reason" json:"reason"` // cancellation or no_show
+	Price      float64   `bson:"price" json:"price"`   // booking price the percentage was taken of
+	AssessedAt time.Time `bson:"assessedAt" json:"assessedAt"`
+}
+
+// CancellationFee is the fee for cancelling a booking of price with untilStart left before it starts.
+// A saved policy always has a tier at 0 hours, only a policy that skipped validation can fall through to no fee.
+func (p CancellationPolicy) CancellationFee(price float64, untilStart time.Duration) float64 {
+	hours := max(untilStart.Hours(), 0)
+	if hours >= p.FreeHours {
+		return 0
+	}
+
+	tiers := slices.Clone(p.Tiers)
+	slices.SortFunc(tiers, func(a, b CancellationTier) int {
+		switch {
+		case a.MinHoursBefore > b.MinHoursBefore:
+			return -1
+		case a.MinHoursBefore < b.MinHoursBefore:
+			return 1
+		}
+		return 0
+	})
+	for _, tier := range tiers {
+		if hours >= tier.MinHoursBefore {
+			return tier.fee(price)
+		}
+	}
+	return 0
+}
+
+func (p CancellationPolicy) NoShowFee(price float64) float64 {
+	return p.NoShow.fee(price)
+}
+
+func (t CancellationTier) fee(price float64) float64 {
+	return math.Round((price*t.FeePercent/100+t.FeeFixed)*100) / 100
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCancellationFee(t *testing.T) {
+	policy := CancellationPolicy{
+		FreeHours: 24,
+		// out of order on purpose, the tiers are sorted before matching
+		Tiers: []CancellationTier{
+			{MinHoursBefore: 0, FeePercent: 50},
+			{MinHoursBefore: 2, FeePercent: 25, FeeFixed: 5},
+		},
+		NoShow: CancellationTier{FeePercent: 100, FeeFixed: 20},
+	}
+
+	tests := []struct {
+		name       string
+		price      float64
+		untilStart time.Duration
+		want       float64
+	}{
+		{"well before the free hours", 100, 48 * time.Hour, 0},
+		{"exactly at the free hours", 100, 24 * time.Hour, 0},
+		{"inside the free hours", 100, 23 * time.Hour, 30},
+		{"exactly at a tier", 100, 2 * time.Hour, 30},
+		{"just past a tier", 100, 2*time.Hour - time.Minute, 50},
+		{"after the start", 100, -time.Hour, 50},
+		{"rounded to cents", 33.33, time.Hour, 16.67},
+		{"free booking still pays the fixed fee", 0, 12 * time.Hour, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.CancellationFee(tt.price, tt.untilStart); got != tt.want {
+				t.Errorf("fee %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoShowFee(t *testing.T) {
+	tests := []struct {
+		name  string
+		tier  CancellationTier
+		price float64
+		want  float64
+	}{
+		{"full price plus a fixed fee", CancellationTier{FeePercent: 100, FeeFixed: 20}, 80, 100},
+		{"percentage only", CancellationTier{FeePercent: 50}, 45.5, 22.75},
+		{"no fee", CancellationTier{}, 80, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := CancellationPolicy{NoShow: tt.tier}
+			if got := policy.NoShowFee(tt.price); got != tt.want {
+				t.Errorf("fee %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,17 +1,62 @@
 package models
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 type Location struct {
 	Type        string    `bson:"type"`
 	Coordinates []float64 `bson:"coordinates"`
 }
 
+// ScheduleTypeBooking marks a schedule created from a booking in the bookings collection,
+// the other types are blocks created by ops that hold the vehicle without a customer
+const (
+	ScheduleTypeBooking     = "booking"
+	ScheduleTypeHold        = "hold"
+	ScheduleTypeMaintenance = "maintenance"
+	ScheduleTypeInspection  = "inspection"
+	ScheduleTypeStaff       = "staff"
+)
+
+var BlockScheduleTypes = []string{ScheduleTypeMaintenance, ScheduleTypeInspection, ScheduleTypeStaff}
+
+// BookingScheduleTypes belong to a record in the bookings collection. Schedules added directly through
+// POST /schedules have no type and carry the caller's own bookingId, which can collide with real booking ids.
+var BookingScheduleTypes = []string{ScheduleTypeBooking, ScheduleTypeHold}
+
 type Schedule struct {
-	Start time.Time `bson:"start"`
-	End   time.Time `bson:"end"`
-	Type  string    `bson:"type"`
-	Id    int       `bson:"sourceId"`
+	Start     time.Time  `bson:"start" json:"start"`
+	End       time.Time  `bson:"end" json:"end"`
+	Type      string     `bson:"type,omitempty" json:"type,omitempty"`
+	BookingId int        `bson:"bookingId,omitempty" json:"bookingId,omitempty"`
+	BlockId   int        `bson:"blockId,omitempty" json:"blockId,omitempty"`
+	Status    string     `bson:"status,omitempty" json:"status,omitempty"` // booking status, empty for schedules added directly
+	Reason    string     `bson:"reason,omitempty" json:"reason,omitempty"`
+	ExpiresAt *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"` // holds stop blocking after this
+}
+
+func (s Schedule) IsBlock() bool {
+	return s.BlockId != 0
+}
+
+func (s Schedule) IsBooking() bool {
+	return slices.Contains(BookingScheduleTypes, s.Type)
+}
+
+// Blocks reports whether the schedule still holds the vehicle,
+// bookings in a terminal status and holds past their expiry do not
+func (s Schedule) Blocks() bool {
+	if s.ExpiresAt != nil && !s.ExpiresAt.After(time.Now()) {
+		return false
+	}
+	return !IsTerminalBookingStatus(s.Status)
+}
+
+// Overlaps uses the same rule as the search pipeline: existing.start < requested.end AND existing.end > requested.start
+func (s Schedule) Overlaps(start, end time.Time) bool {
+	return s.Start.Before(end) && s.End.After(start)
 }
 
 type Vehicle struct {
@@ -20,27 +65,71 @@ type Vehicle struct {
 	ModelName      string     `bson:"modelName"`
 	PlateNumber    string     `bson:"plateNumber"`
 	Seats          int        `bson:"seats"`
+	VehicleTypeId  int        `bson:"vehicleTypeId"`
 	PriceGroupName string     `bson:"priceGroupName"`
 	PriceGroupId   int        `bson:"priceGroupId"`
 	Images         []string   `bson:"images"`
 	Schedules      []Schedule `bson:"schedules"`
 	Lots           []Lot      `bson:"lots"`
+	Discounts      []Discount `bson:"discounts,omitempty"`
+}
+
+// ActiveDiscount is the discount covering the whole window, nil when there is none
+func (v *Vehicle) ActiveDiscount(start, end time.Time) *Discount {
+	for i := range v.Discounts {
+		if v.Discounts[i].Covers(start, end) {
+			return &v.Discounts[i]
+		}
+	}
+	return nil
 }
 
 type Carpark struct {
-	Id                int       `bson:"_id,omitempty"`
-	Name              string    `bson:"name"`
-	PostalCode        string    `bson:"postalCode"`
-	Location          Location  `bson:"location"`
-	Vehicles          []Vehicle `bson:"vehicles"`
-	HasSlashedVehicle bool      `bson:"hasSlashedVehicle"`
-	Address           string    `bson:"address"`
-	Distance          float64   `bson:"dist" json:"distance"`
-	AvailableVehicles int       `bson:"availableVehicles"` // Matches the added field
-	TotalVehicles     int       `bson:"totalVehicles"`     // Matches the added field
+	Id                int           `bson:"_id,omitempty"`
+	Name              string        `bson:"name"`
+	PostalCode        string        `bson:"postalCode"`
+	Location          Location      `bson:"location"`
+	Vehicles          []Vehicle     `bson:"vehicles"`
+	Address           string        `bson:"address"`
+	BufferMinutes     *int          `bson:"bufferMinutes,omitempty"` // overrides the global BufferMinutes setting
+	Closures          []Closure     `bson:"closures,omitempty"`
+	OpeningHours      *OpeningTimes `bson:"openingHours,omitempty"`
+	Distance          float64       `bson:"dist" json:"distance"`
+	AvailableVehicles int           `bson:"availableVehicles"` // Matches the added field
+	TotalVehicles     int           `bson:"totalVehicles"`     // Matches the added field
 }
 
 type Lot struct {
 	Level     string `bson:"level"`
 	LotNumber string `bson:"lotNumber"`
 }
+
+// Closure is a period where the whole carpark is closed and none of its vehicles can be booked
+type Closure struct {
+	Id     int       `bson:"_id" json:"id"`
+	Start  time.Time `bson:"start" json:"start"`
+	End    time.Time `bson:"end" json:"end"`
+	Reason string    `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+func (c Closure) Overlaps(start, end time.Time) bool {
+	return c.Start.Before(end) && c.End.After(start)
+}
+
+// Discount takes Percent off the time charge of the vehicle's price group between Start and End,
+// so the slashed price always follows the current tariff.
+// A booking gets it only when it lies entirely inside the window, the discounts of a vehicle never overlap.
+type Discount struct {
+	Id      int       `bson:"_id" json:"id"`
+	Percent float64   `bson:"percent" json:"percent"`
+	Start   time.Time `bson:"start" json:"start"`
+	End     time.Time `bson:"end" json:"end"`
+}
+
+func (d Discount) Covers(start, end time.Time) bool {
+	return !d.Start.After(start) && !d.End.Before(end)
+}
+
+func (d Discount) Overlaps(start, end time.Time) bool {
+	return d.Start.Before(end) && d.End.After(start)
+}
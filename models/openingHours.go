@@ -0,0 +1,204 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// OpeningHoursLocation is the zone opening hours are written in, Singapore has no daylight saving
+var OpeningHoursLocation = time.FixedZone("Asia/Singapore", 8*60*60)
+
+type OpeningHours struct {
+	Weekday time.Weekday `bson:"weekday" json:"weekday"` // 0 is Sunday
+	Open    string       `bson:"open" json:"open"`       // "08:00"
+	Close   string       `bson:"close" json:"close"`     // "22:00", "24:00" for midnight, before Open to close after midnight
+}
+
+// OpeningException replaces the weekly hours on one date, e.g. a public holiday
+type OpeningException struct {
+	Date   string `bson:"date" json:"date"` // "2026-02-17"
+	Closed bool   `bson:"closed" json:"closed"`
+	Open   string `bson:"open,omitempty" json:"open,omitempty"`
+	Close  string `bson:"close,omitempty" json:"close,omitempty"`
+}
+
+type OpeningTimes struct {
+	Weekly     []OpeningHours     `bson:"weekly" json:"weekly"`
+	Exceptions []OpeningException `bson:"exceptions,omitempty" json:"exceptions,omitempty"`
+}
+
+// OpenPeriod is a stretch of time in which vehicles can be picked up and returned, both ends included
+type OpenPeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+// ParseClock reads an "HH:MM" clock into minutes after midnight, "24:00" is the end of the day
+func ParseClock(clock string) (int, error) {
+	if len(clock) != 5 || clock[2] != ':' {
+		return 0, fmt.Errorf("clock %q is not HH:MM", clock)
+	}
+	hours, err := strconv.Atoi(clock[:2])
+	if err != nil {
+		return 0, fmt.Errorf("clock %q is not HH:MM", clock)
+	}
+	minutes, err := strconv.Atoi(clock[3:])
+	if err != nil {
+		return 0, fmt.Errorf("clock %q is not HH:MM", clock)
+	}
+	if hours < 0 || hours > 24 || minutes < 0 || minutes > 59 || (hours == 24 && minutes != 0) {
+		return 0, fmt.Errorf("clock %q is out of range", clock)
+	}
+	return hours*60 + minutes, nil
+}
+
+// clockSpan is one opening of a day in minutes after midnight, a close before the open is on the next day
+type clockSpan struct {
+	open  int
+	close int
+}
+
+func (s clockSpan) overnight() bool {
+	return s.close < s.open
+}
+
+// parseSpan reads an opening, invalid hours never open so bad data closes the carpark rather than opening it
+func parseSpan(open, close string) (clockSpan, bool) {
+	openMinutes, err := ParseClock(open)
+	if err != nil {
+		return clockSpan{}, false
+	}
+	closeMinutes, err := ParseClock(close)
+	if err != nil || closeMinutes == openMinutes {
+		return clockSpan{}, false
+	}
+	return clockSpan{open: openMinutes, close: closeMinutes}, true
+}
+
+// spansOn returns the openings that start on the local date of day.
+// set is false when the carpark has neither an exception for the date nor any weekly hours, it is then open all day.
+func (o *OpeningTimes) spansOn(day time.Time) (spans []clockSpan, set bool) {
+	date := day.Format(time.DateOnly)
+	for _, exception := range o.Exceptions {
+		if exception.Date != date {
+			continue
+		}
+		if exception.Closed {
+			return nil, true
+		}
+		if span, ok := parseSpan(exception.Open, exception.Close); ok {
+			return []clockSpan{span}, true
+		}
+		return nil, true
+	}
+
+	if len(o.Weekly) == 0 {
+		return nil, false
+	}
+
+	for _, hours := range o.Weekly {
+		if hours.Weekday != day.Weekday() {
+			continue
+		}
+		if span, ok := parseSpan(hours.Open, hours.Close); ok {
+			spans = append(spans, span)
+		}
+	}
+	return spans, true
+}
+
+// IsOpenAt reports whether a vehicle can be picked up or returned at t.
+// A carpark without opening times is always open, so is one without weekly hours outside of exceptions.
+// Hours closing after midnight keep the carpark open into the next date, whatever that date's own hours are.
+func (o *OpeningTimes) IsOpenAt(t time.Time) bool {
+	if o == nil {
+		return true
+	}
+
+	local := t.In(OpeningHoursLocation)
+	minute := local.Hour()*60 + local.Minute()
+
+	today, set := o.spansOn(local)
+	if !set {
+		return true
+	}
+	for _, span := range today {
+		if span.overnight() && minute >= span.open {
+			return true
+		}
+		if !span.overnight() && span.open <= minute && minute <= span.close {
+			return true
+		}
+	}
+
+	// yesterday's hours closing after or at midnight, "24:00" is midnight at the start of today
+	yesterday, _ := o.spansOn(local.AddDate(0, 0, -1))
+	for _, span := range yesterday {
+		if span.overnight() && minute <= span.close {
+			return true
+		}
+		if span.close == 24*60 && minute == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenPeriods lists when the carpark is open between from and to, clipped to the range, sorted and merged.
+// It follows the same rules as IsOpenAt.
+func (o *OpeningTimes) OpenPeriods(from, to time.Time) []OpenPeriod {
+	if !from.Before(to) {
+		return nil
+	}
+	if o == nil {
+		return []OpenPeriod{{Start: from, End: to}}
+	}
+
+	// start a day early for the openings that run past midnight into the range
+	first := from.In(OpeningHoursLocation)
+	day := time.Date(first.Year(), first.Month(), first.Day()-1, 0, 0, 0, 0, OpeningHoursLocation)
+
+	var periods []OpenPeriod
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		spans, set := o.spansOn(day)
+		if !set {
+			spans = []clockSpan{{open: 0, close: 24 * 60}}
+		}
+		for _, span := range spans {
+			start := day.Add(time.Duration(span.open) * time.Minute)
+			end := day.Add(time.Duration(span.close) * time.Minute)
+			if span.overnight() {
+				end = end.AddDate(0, 0, 1)
+			}
+			if end.Before(from) || start.After(to) {
+				continue
+			}
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			periods = append(periods, OpenPeriod{Start: start.UTC(), End: end.UTC()})
+		}
+	}
+
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].Start.Before(periods[j].Start)
+	})
+
+	var merged []OpenPeriod
+	for _, period := range periods {
+		last := len(merged) - 1
+		if last >= 0 && !period.Start.After(merged[last].End) {
+			if period.End.After(merged[last].End) {
+				merged[last].End = period.End
+			}
+			continue
+		}
+		merged = append(merged, period)
+	}
+	return merged
+}
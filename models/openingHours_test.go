@@ -0,0 +1,193 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// sgt reads "2006-01-02 15:04" in OpeningHoursLocation
+func sgt(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, OpeningHoursLocation)
+	if err != nil {
+		t.Fatalf("bad time %q: %v", value, err)
+	}
+	return parsed
+}
+
+// testOpeningTimes is open 08:00-24:00 on Monday, closed on Tuesday, 07:00-18:00 on Wednesday,
+// 20:00-02:00 on Thursday and Friday and 10:00-14:00 plus 16:00-20:00 on Saturday. 2026-10-19 is a Monday.
+func testOpeningTimes() *OpeningTimes {
+	return &OpeningTimes{
+		Weekly: []OpeningHours{
+			{Weekday: time.Monday, Open: "08:00", Close: "24:00"},
+			{Weekday: time.Wednesday, Open: "07:00", Close: "18:00"},
+			{Weekday: time.Thursday, Open: "20:00", Close: "02:00"},
+			{Weekday: time.Friday, Open: "20:00", Close: "02:00"},
+			{Weekday: time.Saturday, Open: "10:00", Close: "14:00"},
+			{Weekday: time.Saturday, Open: "16:00", Close: "20:00"},
+		},
+		Exceptions: []OpeningException{
+			{Date: "2026-10-28", Closed: true},
+			{Date: "2026-10-29", Open: "09:00", Close: "12:00"},
+		},
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		clock   string
+		want    int
+		wantErr bool
+	}{
+		{"00:00", 0, false},
+		{"08:30", 510, false},
+		{"23:59", 1439, false},
+		{"24:00", 1440, false},
+		{"24:01", 0, true},
+		{"25:00", 0, true},
+		{"08:60", 0, true},
+		{"8:00", 0, true},
+		{"08-00", 0, true},
+		{"ab:cd", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.clock, func(t *testing.T) {
+			got, err := ParseClock(tt.clock)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %d minutes, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsOpenAt(t *testing.T) {
+	tests := []struct {
+		name string
+		at   string
+		want bool
+	}{
+		{"before opening", "2026-10-19 07:59", false},
+		{"at opening", "2026-10-19 08:00", true},
+		{"late evening", "2026-10-19 23:59", true},
+		{"24:00 is open at midnight", "2026-10-20 00:00", true},
+		{"closed day after 24:00", "2026-10-20 00:01", false},
+		{"closed day", "2026-10-20 12:00", false},
+		{"at closing", "2026-10-21 18:00", true},
+		{"after closing", "2026-10-21 18:01", false},
+		{"overnight before opening", "2026-10-22 19:59", false},
+		{"overnight evening", "2026-10-22 22:00", true},
+		{"overnight after midnight", "2026-10-23 01:30", true},
+		{"overnight at closing", "2026-10-23 02:00", true},
+		{"overnight after closing", "2026-10-23 02:01", false},
+		{"overnight into a day without hours", "2026-10-24 01:00", true},
+		{"between two openings", "2026-10-24 15:00", false},
+		{"second opening", "2026-10-24 16:00", true},
+		{"no weekly hours on sunday", "2026-10-25 12:00", false},
+		{"closed exception", "2026-10-28 12:00", false},
+		{"exception hours", "2026-10-29 10:00", true},
+		{"exception replaces the weekly hours", "2026-10-29 21:00", false},
+		{"exception replaces overnight hours too", "2026-10-30 01:00", false},
+	}
+
+	hours := testOpeningTimes()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hours.IsOpenAt(sgt(t, tt.at)); got != tt.want {
+				t.Errorf("IsOpenAt(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsOpenAtWithoutWeeklyHours(t *testing.T) {
+	var unset *OpeningTimes
+	if !unset.IsOpenAt(sgt(t, "2026-10-19 03:00")) {
+		t.Error("carpark without opening times must always be open")
+	}
+
+	exceptionsOnly := &OpeningTimes{Exceptions: []OpeningException{{Date: "2026-10-19", Closed: true}}}
+	if exceptionsOnly.IsOpenAt(sgt(t, "2026-10-19 12:00")) {
+		t.Error("closed exception must close the carpark")
+	}
+	if !exceptionsOnly.IsOpenAt(sgt(t, "2026-10-20 03:00")) {
+		t.Error("carpark without weekly hours must be open outside its exceptions")
+	}
+}
+
+func TestOpenPeriods(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want [][2]string
+	}{
+		{
+			name: "24:00 runs to midnight and the closed day has nothing",
+			from: "2026-10-19 00:00",
+			to:   "2026-10-21 00:00",
+			want: [][2]string{{"2026-10-19 08:00", "2026-10-20 00:00"}},
+		},
+		{
+			name: "clipped to the range",
+			from: "2026-10-19 12:00",
+			to:   "2026-10-19 14:00",
+			want: [][2]string{{"2026-10-19 12:00", "2026-10-19 14:00"}},
+		},
+		{
+			name: "overnight hours run into the next day",
+			from: "2026-10-22 00:00",
+			to:   "2026-10-24 00:00",
+			want: [][2]string{{"2026-10-22 20:00", "2026-10-23 02:00"}, {"2026-10-23 20:00", "2026-10-24 00:00"}},
+		},
+		{
+			name: "previous day's overnight hours reach into the range",
+			from: "2026-10-24 00:00",
+			to:   "2026-10-25 00:00",
+			want: [][2]string{{"2026-10-24 00:00", "2026-10-24 02:00"}, {"2026-10-24 10:00", "2026-10-24 14:00"}, {"2026-10-24 16:00", "2026-10-24 20:00"}},
+		},
+		{
+			name: "exceptions replace the weekly hours",
+			from: "2026-10-28 00:00",
+			to:   "2026-10-30 00:00",
+			want: [][2]string{{"2026-10-29 09:00", "2026-10-29 12:00"}},
+		},
+		{
+			name: "empty range",
+			from: "2026-10-19 12:00",
+			to:   "2026-10-19 12:00",
+			want: [][2]string{},
+		},
+	}
+
+	hours := testOpeningTimes()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hours.OpenPeriods(sgt(t, tt.from), sgt(t, tt.to))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d periods %v, want %v", len(got), got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(sgt(t, tt.want[i][0])) || !got[i].End.Equal(sgt(t, tt.want[i][1])) {
+					t.Errorf("period %d is %s - %s, want %s - %s", i,
+						got[i].Start.In(OpeningHoursLocation), got[i].End.In(OpeningHoursLocation), tt.want[i][0], tt.want[i][1])
+				}
+			}
+		})
+	}
+}
+
+// every period OpenPeriods returns must be open at both ends, so a slot it offers can be booked
+func TestOpenPeriodsAgreeWithIsOpenAt(t *testing.T) {
+	hours := testOpeningTimes()
+	for _, period := range hours.OpenPeriods(sgt(t, "2026-10-18 00:00"), sgt(t, "2026-11-01 00:00")) {
+		if !hours.IsOpenAt(period.Start) || !hours.IsOpenAt(period.End) {
+			t.Errorf("period %s - %s is not open at both ends",
+				period.Start.In(OpeningHoursLocation), period.End.In(OpeningHoursLocation))
+		}
+	}
+}
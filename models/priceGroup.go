@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// PriceGroup is the tariff shared by every vehicle with the same PriceGroupId, amounts are in dollars
+type PriceGroup struct {
+	Id                int        `bson:"_id" json:"id"`
+	Name              string     `bson:"name" json:"name"`
+	HourlyRate        float64    `bson:"hourlyRate" json:"hourlyRate"`
+	WeekendHourlyRate float64    `bson:"weekendHourlyRate,omitempty" json:"weekendHourlyRate,omitempty"` // Saturday and Sunday, 0 uses HourlyRate
+	DailyCap          float64    `bson:"dailyCap,omitempty" json:"dailyCap,omitempty"`                   // most the time charge of one day can reach, 0 is uncapped
+	PerKmRate         float64    `bson:"perKmRate,omitempty" json:"perKmRate,omitempty"`
+	PeakBands         []PeakBand `bson:"peakBands,omitempty" json:"peakBands,omitempty"`
+	CreatedAt         time.Time  `bson:"createdAt" json:"createdAt"`
+}
+
+// PeakBand charges its own hourly rate between Start and End on the given weekdays, in Asia/Singapore time.
+// Bands do not wrap midnight, a late night band is written as two bands.
+type PeakBand struct {
+	Weekdays   []time.Weekday `bson:"weekdays,omitempty" json:"weekdays,omitempty"` // empty is every day
+	Start      string         `bson:"start" json:"start"`                           // "17:00"
+	End        string         `bson:"end" json:"end"`                               // "20:00", "24:00" for midnight
+	HourlyRate float64        `bson:"hourlyRate" json:"hourlyRate"`
+}
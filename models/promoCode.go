@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	PromoTypePercentage = "percentage"
+	PromoTypeFixed      = "fixed"
+)
+
+// PromoCode discounts the total of a quote or booking. Redemptions counts the global uses,
+// the uses per user are kept in PromoRedemption documents.
+type PromoCode struct {
+	Code              string    `bson:"_id" json:"code"`
+	Type              string    `bson:"type" json:"type"`   // percentage or fixed
+	Value             float64   `bson:"value" json:"value"` // percent off, or dollars off
+	ValidFrom         time.Time `bson:"validFrom" json:"validFrom"`
+	ValidTo           time.Time `bson:"validTo" json:"validTo"`
+	MaxRedemptions    int       `bson:"maxRedemptions" json:"maxRedemptions"` // 0 is unlimited
+	MaxPerUser        int       `bson:"maxPerUser" json:"maxPerUser"`         // 0 is unlimited
+	Redemptions       int       `bson:"redemptions" json:"redemptions"`
+	MinBookingMinutes int       `bson:"minBookingMinutes,omitempty" json:"minBookingMinutes,omitempty"`
+	PriceGroupIds     []int     `bson:"priceGroupIds,omitempty" json:"priceGroupIds,omitempty"` // empty is every price group
+	CarparkIds        []int     `bson:"carparkIds,omitempty" json:"carparkIds,omitempty"`       // empty is every carpark
+	CreatedAt         time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// DiscountOn is the amount taken off total, never more than total itself
+func (p *PromoCode) DiscountOn(total float64) float64 {
+	discount := p.Value
+	if p.Type == PromoTypePercentage {
+		discount = total * p.Value / 100
+	}
+	return min(discount, total)
+}
+
+// PromoRedemption counts the uses of a code by one user, its id is "<code>:<userId>"
+type PromoRedemption struct {
+	Id     string `bson:"_id" json:"id"`
+	Code   string `bson:"code" json:"code"`
+	UserId int    `bson:"userId" json:"userId"`
+	Count  int    `bson:"count" json:"count"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	WatchStatusActive   = "active"
+	WatchStatusNotified = "notified"
+)
+
+// Watch waits for a vehicle to become free in a carpark, or anywhere within RadiusKm of a point, for a window
+type Watch struct {
+	Id             int        `bson:"_id" json:"id"`
+	UserId         int        `bson:"userId" json:"userId"`
+	CarparkId      int        `bson:"carparkId,omitempty" json:"carparkId,omitempty"`
+	Longitude      float64    `bson:"longitude,omitempty" json:"longitude,omitempty"`
+	Latitude       float64    `bson:"latitude,omitempty" json:"latitude,omitempty"`
+	RadiusKm       float64    `bson:"radiusKm,omitempty" json:"radiusKm,omitempty"`
+	Start          time.Time  `bson:"start" json:"start"`
+	End            time.Time  `bson:"end" json:"end"`
+	PriceGroupIds  []int      `bson:"priceGroupIds,omitempty" json:"priceGroupIds,omitempty"`
+	VehicleTypeIds []int      `bson:"vehicleTypeIds,omitempty" json:"vehicleTypeIds,omitempty"`
+	NumSeats       int        `bson:"numSeats,omitempty" json:"numSeats,omitempty"`
+	Status         string     `bson:"status" json:"status"`
+	CreatedAt      time.Time  `bson:"createdAt" json:"createdAt"`
+	NotifiedAt     *time.Time `bson:"notifiedAt,omitempty" json:"notifiedAt,omitempty"`
+}
+
+// Notification is emitted when a watch finds an available vehicle
+type Notification struct {
+	WatchId           int       `json:"watchId"`
+	UserId            int       `json:"userId"`
+	CarparkId         int       `json:"carparkId"`
+	CarparkName       string    `json:"carparkName"`
+	Start             time.Time `json:"start"`
+	End               time.Time `json:"end"`
+	AvailableVehicles int       `json:"availableVehicles"`
+	CreatedAt         time.Time `json:"createdAt"`
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// busyWindows returns the blocking schedules padded by buffer on both sides and the carpark closures
+// that touch [from, to), sorted and merged so that no two windows overlap.
+// Closures are not padded, like addSchedule and vehicleIsFree a booking may end right as a closure starts.
+func busyWindows(schedules []models.Schedule, closures []models.Closure, from, to time.Time, buffer time.Duration) []dtos.TimeWindow {
+	var windows []dtos.TimeWindow
+	add := func(start, end time.Time) {
+		if start.Before(to) && end.After(from) {
+			windows = append(windows, dtos.TimeWindow{Start: start, End: end})
+		}
+	}
+	for _, schedule := range schedules {
+		if schedule.Blocks() {
+			add(schedule.Start.Add(-buffer), schedule.End.Add(buffer))
+		}
+	}
+	for _, closure := range closures {
+		add(closure.Start, closure.End)
+	}
+
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].Start.Before(windows[j].Start)
+	})
+
+	var merged []dtos.TimeWindow
+	for _, window := range windows {
+		last := len(merged) - 1
+		if last >= 0 && !window.Start.After(merged[last].End) {
+			if window.End.After(merged[last].End) {
+				merged[last].End = window.End
+			}
+			continue
+		}
+		merged = append(merged, window)
+	}
+	return merged
+}
+
+// freeWindows returns the gaps between the merged busy windows inside [from, to)
+func freeWindows(busy []dtos.TimeWindow, from, to time.Time) []dtos.TimeWindow {
+	var free []dtos.TimeWindow
+	cursor := from
+	for _, window := range busy {
+		if window.Start.After(cursor) {
+			free = append(free, dtos.TimeWindow{Start: cursor, End: minTime(window.Start, to)})
+		}
+		if window.End.After(cursor) {
+			cursor = window.End
+		}
+		if !cursor.Before(to) {
+			return free
+		}
+	}
+	if cursor.Before(to) {
+		free = append(free, dtos.TimeWindow{Start: cursor, End: to})
+	}
+	return free
+}
+
+// openWithin trims a free window so it starts and ends while the carpark is open,
+// ok is false when the carpark does not open at all inside the window
+func openWithin(open []models.OpenPeriod, window dtos.TimeWindow) (dtos.TimeWindow, bool) {
+	trimmed := dtos.TimeWindow{}
+	found := false
+	for _, period := range open {
+		if period.End.Before(window.Start) || period.Start.After(window.End) {
+			continue
+		}
+		if !found {
+			trimmed.Start = maxTime(period.Start, window.Start)
+			found = true
+		}
+		trimmed.End = minTime(period.End, window.End)
+	}
+	return trimmed, found
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// vehicleMatches applies the same filters as the search, empty filters match everything
+func vehicleMatches(vehicle *models.Vehicle, priceGroupIds, vehicleTypeIds []int, numSeats int) bool {
+	if len(priceGroupIds) > 0 && !slices.Contains(priceGroupIds, vehicle.PriceGroupId) {
+		return false
+	}
+	if len(vehicleTypeIds) > 0 && !slices.Contains(vehicleTypeIds, vehicle.VehicleTypeId) {
+		return false
+	}
+	return vehicle.Seats >= numSeats
+}
+
+// vehicleIsFree is the search pipeline's availability rule for a single vehicle:
+// the carpark is open at pick-up and return, not closed, and no schedule is within the buffer of the window
+func vehicleIsFree(carpark *models.Carpark, vehicle *models.Vehicle, buffer time.Duration, start, end time.Time) bool {
+	if !carpark.OpeningHours.IsOpenAt(start) || !carpark.OpeningHours.IsOpenAt(end) {
+		return false
+	}
+
+	for _, closure := range carpark.Closures {
+		if closure.Overlaps(start, end) {
+			return false
+		}
+	}
+
+	for _, schedule := range vehicle.Schedules {
+		if schedule.Blocks() && schedule.Overlaps(start.Add(-buffer), end.Add(buffer)) {
+			return false
+		}
+	}
+	return true
+}
+
+// distanceKm is the great circle distance between two points, same as $geoNear's spherical distance
+func distanceKm(lon1, lat1, lon2, lat2 float64) float64 {
+	const earthRadiusKm = 6378.1
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
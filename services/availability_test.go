@@ -0,0 +1,156 @@
+package services
+
+import (
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	"testing"
+	"time"
+)
+
+func windowsEqual(t *testing.T, got []dtos.TimeWindow, want [][2]string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d windows %v, want %v", len(got), got, want)
+	}
+	for i := range got {
+		if !got[i].Start.Equal(sgt(t, want[i][0])) || !got[i].End.Equal(sgt(t, want[i][1])) {
+			t.Errorf("window %d is %s - %s, want %s - %s", i,
+				got[i].Start.In(models.OpeningHoursLocation), got[i].End.In(models.OpeningHoursLocation), want[i][0], want[i][1])
+		}
+	}
+}
+
+func TestBusyWindows(t *testing.T) {
+	expired := time.Now().Add(-time.Hour)
+	schedule := func(start, end string) models.Schedule {
+		return models.Schedule{Type: models.ScheduleTypeBooking, Status: models.BookingStatusReserved, Start: sgt(t, start), End: sgt(t, end)}
+	}
+	cancelled := schedule("2026-10-19 12:00", "2026-10-19 13:00")
+	cancelled.Status = models.BookingStatusCancelled
+	expiredHold := schedule("2026-10-19 14:00", "2026-10-19 15:00")
+	expiredHold.Type = models.ScheduleTypeHold
+	expiredHold.Status = models.BookingStatusHeld
+	expiredHold.ExpiresAt = &expired
+
+	tests := []struct {
+		name      string
+		schedules []models.Schedule
+		closures  []models.Closure
+		buffer    time.Duration
+		want      [][2]string
+	}{
+		{
+			name:      "sorted",
+			schedules: []models.Schedule{schedule("2026-10-19 15:00", "2026-10-19 16:00"), schedule("2026-10-19 09:00", "2026-10-19 10:00")},
+			want:      [][2]string{{"2026-10-19 09:00", "2026-10-19 10:00"}, {"2026-10-19 15:00", "2026-10-19 16:00"}},
+		},
+		{
+			name:      "overlapping and touching schedules are merged",
+			schedules: []models.Schedule{schedule("2026-10-19 09:00", "2026-10-19 11:00"), schedule("2026-10-19 10:00", "2026-10-19 12:00"), schedule("2026-10-19 12:00", "2026-10-19 13:00")},
+			want:      [][2]string{{"2026-10-19 09:00", "2026-10-19 13:00"}},
+		},
+		{
+			name:      "buffer pads both sides and can merge",
+			schedules: []models.Schedule{schedule("2026-10-19 09:00", "2026-10-19 10:00"), schedule("2026-10-19 11:00", "2026-10-19 12:00")},
+			buffer:    30 * time.Minute,
+			want:      [][2]string{{"2026-10-19 08:30", "2026-10-19 12:30"}},
+		},
+		{
+			name:      "closures are not padded by the buffer",
+			schedules: []models.Schedule{schedule("2026-10-19 09:00", "2026-10-19 10:00")},
+			closures:  []models.Closure{{Id: 1, Start: sgt(t, "2026-10-19 14:00"), End: sgt(t, "2026-10-19 16:00")}},
+			buffer:    30 * time.Minute,
+			want:      [][2]string{{"2026-10-19 08:30", "2026-10-19 10:30"}, {"2026-10-19 14:00", "2026-10-19 16:00"}},
+		},
+		{
+			name:      "cancelled bookings and expired holds do not block",
+			schedules: []models.Schedule{cancelled, expiredHold, schedule("2026-10-19 09:00", "2026-10-19 10:00")},
+			want:      [][2]string{{"2026-10-19 09:00", "2026-10-19 10:00"}},
+		},
+		{
+			name:      "outside the range is dropped, inside the range is not clipped",
+			schedules: []models.Schedule{schedule("2026-10-18 09:00", "2026-10-18 10:00"), schedule("2026-10-19 23:00", "2026-10-20 02:00")},
+			want:      [][2]string{{"2026-10-19 23:00", "2026-10-20 02:00"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := busyWindows(tt.schedules, tt.closures, sgt(t, "2026-10-19 00:00"), sgt(t, "2026-10-20 00:00"), tt.buffer)
+			windowsEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestFreeWindows(t *testing.T) {
+	busy := func(start, end string) dtos.TimeWindow {
+		return dtos.TimeWindow{Start: sgt(t, start), End: sgt(t, end)}
+	}
+
+	tests := []struct {
+		name string
+		busy []dtos.TimeWindow
+		want [][2]string
+	}{
+		{
+			name: "nothing booked",
+			want: [][2]string{{"2026-10-19 00:00", "2026-10-20 00:00"}},
+		},
+		{
+			name: "gaps around a booking",
+			busy: []dtos.TimeWindow{busy("2026-10-19 09:00", "2026-10-19 10:00")},
+			want: [][2]string{{"2026-10-19 00:00", "2026-10-19 09:00"}, {"2026-10-19 10:00", "2026-10-20 00:00"}},
+		},
+		{
+			name: "bookings running over both ends of the range",
+			busy: []dtos.TimeWindow{busy("2026-10-18 22:00", "2026-10-19 02:00"), busy("2026-10-19 23:00", "2026-10-20 03:00")},
+			want: [][2]string{{"2026-10-19 02:00", "2026-10-19 23:00"}},
+		},
+		{
+			name: "fully booked",
+			busy: []dtos.TimeWindow{busy("2026-10-18 00:00", "2026-10-21 00:00")},
+			want: [][2]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := freeWindows(tt.busy, sgt(t, "2026-10-19 00:00"), sgt(t, "2026-10-20 00:00"))
+			windowsEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestOpenWithin(t *testing.T) {
+	// open 07:00 to 23:00 every day
+	hours := &models.OpeningTimes{}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		hours.Weekly = append(hours.Weekly, models.OpeningHours{Weekday: weekday, Open: "07:00", Close: "23:00"})
+	}
+	open := hours.OpenPeriods(sgt(t, "2026-10-19 00:00"), sgt(t, "2026-10-22 00:00"))
+
+	tests := []struct {
+		name   string
+		window [2]string
+		want   [2]string
+		wantOk bool
+	}{
+		{"inside the opening hours", [2]string{"2026-10-19 09:00", "2026-10-19 12:00"}, [2]string{"2026-10-19 09:00", "2026-10-19 12:00"}, true},
+		{"trimmed at both ends", [2]string{"2026-10-19 05:00", "2026-10-19 23:30"}, [2]string{"2026-10-19 07:00", "2026-10-19 23:00"}, true},
+		{"spanning the night", [2]string{"2026-10-19 20:00", "2026-10-20 09:00"}, [2]string{"2026-10-19 20:00", "2026-10-20 09:00"}, true},
+		{"ends during the night", [2]string{"2026-10-19 20:00", "2026-10-20 05:00"}, [2]string{"2026-10-19 20:00", "2026-10-19 23:00"}, true},
+		{"only at night", [2]string{"2026-10-19 23:30", "2026-10-20 06:00"}, [2]string{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := openWithin(open, dtos.TimeWindow{Start: sgt(t, tt.window[0]), End: sgt(t, tt.window[1])})
+			if ok != tt.wantOk {
+				t.Fatalf("got ok %v, want %v", ok, tt.wantOk)
+			}
+			if ok {
+				windowsEqual(t, []dtos.TimeWindow{got}, [][2]string{tt.want})
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	"example/golang-learn/utilities/db"
+	apperrors "example/golang-learn/utilities/errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// BlockService manages non-booking schedules such as maintenance, they hold a vehicle like a booking does
+type BlockService struct {
+	counters       *mongo.Collection
+	carparkService *CarparkService
+}
+
+func NewBlockService(counters *mongo.Collection, carparkService *CarparkService) *BlockService {
+	return &BlockService{
+		counters:       counters,
+		carparkService: carparkService,
+	}
+}
+
+func (s *BlockService) AddBlock(vehicleId int, req dtos.AddBlockRequest) (*models.Schedule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 1. Validate, blocks are not held to the customer booking rules
+	fields := make(map[string][]string)
+	if !slices.Contains(models.BlockScheduleTypes, req.Type) {
+		fields["type"] = append(fields["type"], fmt.Sprintf("Type must be one of %v", models.BlockScheduleTypes))
+	}
+	if !req.End.After(req.Start) {
+		fields["end"] = append(fields["end"], "End must be after start")
+	}
+	if len(fields) > 0 {
+		return nil, &apperrors.ValidationError{Fields: fields}
+	}
+
+	carpark, _, err := s.carparkService.findVehicle(ctx, vehicleId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Generate the block id
+	blockId, err := db.NextSequence(ctx, s.counters, "blocks")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate block id: %w", err)
+	}
+
+	// 3. Push it like any other schedule so overlaps are rejected the same way
+	block := models.Schedule{
+		Type:    req.Type,
+		BlockId: blockId,
+		Start:   req.Start.UTC(),
+		End:     req.End.UTC(),
+		Reason:  req.Reason,
+	}
+	if err = s.carparkService.addSchedule(ctx, carpark.Id, vehicleId, block); err != nil {
+		return nil, err
+	}
+
+	return &block, nil
+}
+
+func (s *BlockService) GetBlocks(vehicleId int) ([]models.Schedule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, vehicle, err := s.carparkService.findVehicle(ctx, vehicleId)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := []models.Schedule{}
+	for _, schedule := range vehicle.Schedules {
+		if schedule.IsBlock() {
+			blocks = append(blocks, schedule)
+		}
+	}
+	return blocks, nil
+}
+
+func (s *BlockService) RemoveBlock(vehicleId, blockId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	carpark, vehicle, err := s.carparkService.findVehicle(ctx, vehicleId)
+	if err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(vehicle.Schedules, func(schedule models.Schedule) bool {
+		return schedule.BlockId == blockId
+	}) {
+		return fmt.Errorf("block %d on vehicle %d: %w", blockId, vehicleId, apperrors.BlockNotFound)
+	}
+
+	return s.carparkService.pullSchedules(ctx, carpark.Id, vehicleId, bson.M{"blockId": blockId})
+}
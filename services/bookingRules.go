@@ -0,0 +1,86 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	apperrors "example/golang-learn/utilities/errors"
+)
+
+// bookingRules are the limits every booking window has to respect, all read from settings
+type bookingRules struct {
+	minDuration time.Duration
+	maxDuration time.Duration
+	granularity time.Duration
+	maxLead     time.Duration
+}
+
+func loadBookingRules(settingService *SettingService) (bookingRules, error) {
+	minMinutes, err := settingService.GetInt(SettingMinBookingMinutes, 30)
+	if err != nil {
+		return bookingRules{}, fmt.Errorf("failed to read min booking duration: %w", err)
+	}
+	maxMinutes, err := settingService.GetInt(SettingMaxBookingMinutes, 7*24*60)
+	if err != nil {
+		return bookingRules{}, fmt.Errorf("failed to read max booking duration: %w", err)
+	}
+	granularityMinutes, err := settingService.GetInt(SettingBookingGranularityMinutes, 15)
+	if err != nil {
+		return bookingRules{}, fmt.Errorf("failed to read booking granularity: %w", err)
+	}
+	maxLeadDays, err := settingService.GetInt(SettingMaxLeadDays, 90)
+	if err != nil {
+		return bookingRules{}, fmt.Errorf("failed to read max lead time: %w", err)
+	}
+
+	return bookingRules{
+		minDuration: time.Duration(minMinutes) * time.Minute,
+		maxDuration: time.Duration(maxMinutes) * time.Minute,
+		granularity: time.Duration(granularityMinutes) * time.Minute,
+		maxLead:     time.Duration(maxLeadDays) * 24 * time.Hour,
+	}, nil
+}
+
+// validate collects every broken rule per field instead of stopping at the first one
+func (r bookingRules) validate(start, end, now time.Time) error {
+	fields := make(map[string][]string)
+
+	if !end.After(start) {
+		fields["end"] = append(fields["end"], "End must be after start")
+	} else {
+		duration := end.Sub(start)
+		if duration < r.minDuration {
+			fields["end"] = append(fields["end"], fmt.Sprintf("Booking must be at least %v", r.minDuration))
+		}
+		if duration > r.maxDuration {
+			fields["end"] = append(fields["end"], fmt.Sprintf("Booking must be at most %v", r.maxDuration))
+		}
+	}
+
+	if r.granularity > 0 {
+		if start.Truncate(r.granularity) != start {
+			fields["start"] = append(fields["start"], fmt.Sprintf("Start must be aligned to %v", r.granularity))
+		}
+		if end.Truncate(r.granularity) != end {
+			fields["end"] = append(fields["end"], fmt.Sprintf("End must be aligned to %v", r.granularity))
+		}
+	}
+
+	if start.After(now.Add(r.maxLead)) {
+		fields["start"] = append(fields["start"], fmt.Sprintf("Start must be within %v from now", r.maxLead))
+	}
+
+	if len(fields) > 0 {
+		return &apperrors.ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// validateBookingWindow checks a window against the current booking rules
+func validateBookingWindow(settingService *SettingService, start, end time.Time) error {
+	rules, err := loadBookingRules(settingService)
+	if err != nil {
+		return err
+	}
+	return rules.validate(start, end, time.Now())
+}
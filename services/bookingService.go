@@ -0,0 +1,761 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	"example/golang-learn/utilities/db"
+	apperrors "example/golang-learn/utilities/errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type BookingService struct {
+	client         *mongo.Client
+	coll           *mongo.Collection
+	counters       *mongo.Collection
+	carparkService *CarparkService
+	settingService *SettingService
+	pricingService *PricingService
+	promoService   *PromoService
+}
+
+func NewBookingService(client *mongo.Client, coll *mongo.Collection, counters *mongo.Collection, carparkService *CarparkService, settingService *SettingService, pricingService *PricingService, promoService *PromoService) *BookingService {
+	return &BookingService{
+		client:         client,
+		coll:           coll,
+		counters:       counters,
+		carparkService: carparkService,
+		settingService: settingService,
+		pricingService: pricingService,
+		promoService:   promoService,
+	}
+}
+
+func (s *BookingService) CreateBooking(req dtos.CreateBookingRequest) (*models.Booking, error) {
+	return s.createBooking(req, models.BookingStatusReserved, nil)
+}
+
+// CreateHold holds the vehicle for HoldMinutes while the customer pays, see ConfirmHold
+func (s *BookingService) CreateHold(req dtos.CreateBookingRequest) (*models.Booking, error) {
+	holdMinutes, err := s.settingService.GetInt(SettingHoldMinutes, 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hold duration: %w", err)
+	}
+
+	expiresAt := time.Now().UTC().Add(time.Duration(holdMinutes) * time.Minute)
+	return s.createBooking(req, models.BookingStatusHeld, &expiresAt)
+}
+
+func (s *BookingService) createBooking(req dtos.CreateBookingRequest, status string, expiresAt *time.Time) (*models.Booking, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := validateBookingWindow(s.settingService, req.Start, req.End); err != nil {
+		return nil, err
+	}
+	if err := s.carparkService.checkOpeningHours(ctx, req.CarparkId, req.Start, req.End); err != nil {
+		return nil, err
+	}
+
+	// 1. Price the booking before anything is written, an unusable promo code rejects it
+	var promo *models.PromoCode
+	var promoDiscount, price float64
+	if req.PromoCode != "" {
+		quote, applied, err := s.pricingService.quote(ctx, dtos.QuoteRequest{
+			UserId:    req.UserId,
+			VehicleId: req.VehicleId,
+			Start:     req.Start,
+			End:       req.End,
+			PromoCode: req.PromoCode,
+		})
+		if err != nil {
+			return nil, err
+		}
+		promo, promoDiscount, price = applied, quote.PromoDiscount, quote.Total
+	} else {
+		prices, err := s.agreedPrices(ctx, req.VehicleId, []dtos.TimeWindow{{Start: req.Start, End: req.End}})
+		if err != nil {
+			return nil, err
+		}
+		price = prices[0]
+	}
+
+	// 2. Generate the booking id
+	bookingId, err := db.NextSequence(ctx, s.counters, "bookings")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate booking id: %w", err)
+	}
+
+	now := time.Now().UTC()
+	booking := models.Booking{
+		Id:        bookingId,
+		UserId:    req.UserId,
+		CarparkId: req.CarparkId,
+		VehicleId: req.VehicleId,
+		Start:     req.Start.UTC(),
+		End:       req.End.UTC(),
+		Status:    status,
+		StatusHistory: []models.BookingStatusChange{
+			{Status: status, At: now},
+		},
+		ExpiresAt: expiresAt,
+		Price:     &price,
+		CreatedAt: now,
+	}
+
+	// 3. Redeem the code, this is where its limits are enforced. Every later failure gives the use back.
+	releasePromo := func() {}
+	if promo != nil {
+		if err = s.promoService.redeem(ctx, promo, req.UserId); err != nil {
+			return nil, err
+		}
+		booking.PromoCode = promo.Code
+		booking.PromoDiscount = promoDiscount
+		releasePromo = func() {
+			if releaseErr := s.promoService.release(ctx, promo.Code, req.UserId); releaseErr != nil {
+				log.Error().Err(releaseErr).Int("bookingId", booking.Id).Msg("failed to release promo code of unsaved booking")
+			}
+		}
+	}
+
+	// 4. Claim the slot on the vehicle first, this is where overlapping bookings are rejected
+	schedule := models.Schedule{
+		Type:      models.ScheduleTypeBooking,
+		BookingId: booking.Id,
+		Status:    booking.Status,
+		Start:     booking.Start,
+		End:       booking.End,
+		ExpiresAt: expiresAt,
+	}
+	if status == models.BookingStatusHeld {
+		schedule.Type = models.ScheduleTypeHold
+	}
+
+	// 5. Record the booking in the same transaction so a schedule never outlives a failed insert
+	session, err := s.client.StartSession()
+	if err != nil {
+		releasePromo()
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		if err := s.carparkService.addSchedule(ctx, booking.CarparkId, booking.VehicleId, schedule); err != nil {
+			return nil, err
+		}
+		if _, err := s.coll.InsertOne(ctx, booking); err != nil {
+			return nil, fmt.Errorf("failed to save booking: %w", err)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		releasePromo()
+		return nil, err
+	}
+
+	return &booking, nil
+}
+
+// ConfirmHold turns an unexpired hold into a reserved booking on the same slot
+func (s *BookingService) ConfirmHold(bookingId int) (*models.Booking, error) {
+	booking, err := s.GetBooking(bookingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.Status != models.BookingStatusHeld {
+		return nil, fmt.Errorf("booking %d is %s: %w", bookingId, booking.Status, apperrors.InvalidStatusTransition)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 1. Confirm only while the hold is still valid, so a hold the sweeper is about to expire cannot be confirmed
+	now := time.Now().UTC()
+	change := models.BookingStatusChange{Status: models.BookingStatusReserved, At: now}
+	filter := bson.M{
+		"_id":       bookingId,
+		"status":    models.BookingStatusHeld,
+		"expiresAt": bson.M{"$gt": now},
+	}
+	update := bson.M{
+		"$set":   bson.M{"status": change.Status},
+		"$unset": bson.M{"expiresAt": ""},
+		"$push":  bson.M{"statusHistory": change},
+	}
+
+	session, err := s.client.StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		result, err := s.coll.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return nil, fmt.Errorf("failed to confirm hold %d: %w", bookingId, err)
+		}
+		if result.MatchedCount == 0 {
+			return nil, fmt.Errorf("hold %d: %w", bookingId, apperrors.HoldExpired)
+		}
+
+		// 2. The schedule becomes a normal booking that no longer expires, with the same expiry guard.
+		// Both writes are one transaction so a schedule that already expired rolls the booking back.
+		return nil, s.carparkService.confirmHoldSchedule(ctx, booking.CarparkId, booking.VehicleId, bookingId, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	booking.Status = change.Status
+	booking.ExpiresAt = nil
+	booking.StatusHistory = append(booking.StatusHistory, change)
+	return booking, nil
+}
+
+// ReleaseHold cancels a hold the customer abandoned, freeing the vehicle before the hold expires
+func (s *BookingService) ReleaseHold(bookingId int) (*models.Booking, error) {
+	booking, err := s.GetBooking(bookingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.Status != models.BookingStatusHeld {
+		return nil, fmt.Errorf("booking %d is %s: %w", bookingId, booking.Status, apperrors.InvalidStatusTransition)
+	}
+
+	return s.UpdateStatus(bookingId, models.BookingStatusCancelled)
+}
+
+// SweepExpiredHolds marks holds past their expiry as expired every interval until ctx is done.
+// Availability already ignores them once expired, this keeps the booking records and schedules in step.
+func (s *BookingService) SweepExpiredHolds(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.expireHolds(ctx); err != nil {
+				log.Error().Err(err).Msg("failed to sweep expired holds")
+			}
+		}
+	}
+}
+
+func (s *BookingService) expireHolds(ctx context.Context) error {
+	findCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"status":    models.BookingStatusHeld,
+		"expiresAt": bson.M{"$lte": time.Now().UTC()},
+	}
+	cursor, err := s.coll.Find(findCtx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to find expired holds: %w", err)
+	}
+	defer cursor.Close(findCtx)
+
+	var holds []models.Booking
+	if err := cursor.All(findCtx, &holds); err != nil {
+		return fmt.Errorf("decoding failed: %w", err)
+	}
+
+	for _, hold := range holds {
+		// a hold confirmed since the find is no longer held, UpdateStatus rejects it and we move on
+		if _, err := s.UpdateStatus(hold.Id, models.BookingStatusExpired); err != nil {
+			log.Warn().Err(err).Int("bookingId", hold.Id).Msg("could not expire hold")
+		}
+	}
+
+	return nil
+}
+
+// UpdateStatus moves a booking along reserved -> active -> completed, or to cancelled / no-show from reserved
+func (s *BookingService) UpdateStatus(bookingId int, status string) (*models.Booking, error) {
+	booking, err := s.GetBooking(bookingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !models.CanTransitionBooking(booking.Status, status) {
+		return nil, fmt.Errorf("booking %d from %s to %s: %w", bookingId, booking.Status, status, apperrors.InvalidStatusTransition)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 1. Late cancellations and no-shows are charged by the cancellation policy
+	change := models.BookingStatusChange{Status: status, At: time.Now().UTC()}
+	fee, err := s.bookingFee(ctx, booking, status, change.At)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Only apply the change if nobody moved the booking since it was read, the fee is saved with it.
+	// The booking and its schedule are written in one transaction so they never disagree.
+	set := bson.M{"status": status}
+	if fee != nil {
+		set["fee"] = fee
+	}
+	filter := bson.M{"_id": bookingId, "status": booking.Status}
+	update := bson.M{
+		"$set":  set,
+		"$push": bson.M{"statusHistory": change},
+	}
+
+	session, err := s.client.StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		result, err := s.coll.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update booking %d: %w", bookingId, err)
+		}
+		if result.MatchedCount == 0 {
+			return nil, fmt.Errorf("booking %d changed while updating to %s: %w", bookingId, status, apperrors.InvalidStatusTransition)
+		}
+
+		// 3. Mirror the status onto the vehicle schedule, terminal statuses free the vehicle
+		return nil, s.carparkService.updateScheduleStatus(ctx, booking.CarparkId, booking.VehicleId, bookingId, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if models.IsTerminalBookingStatus(status) {
+		s.carparkService.availabilityChanged(booking.CarparkId)
+	}
+
+	// 4. A hold that never became a booking gives its promo code use back
+	if booking.Status == models.BookingStatusHeld && booking.PromoCode != "" {
+		if err = s.promoService.release(ctx, booking.PromoCode, booking.UserId); err != nil {
+			log.Error().Err(err).Int("bookingId", bookingId).Msg("failed to release promo code of hold")
+		}
+	}
+
+	booking.Status = status
+	booking.StatusHistory = append(booking.StatusHistory, change)
+	booking.Fee = fee
+	return booking, nil
+}
+
+// Reschedule extends, shortens or moves a booking without releasing its slot in between
+func (s *BookingService) Reschedule(bookingId int, req dtos.RescheduleBookingRequest) (*models.Booking, error) {
+	booking, err := s.GetBooking(bookingId)
+	if err != nil {
+		return nil, err
+	}
+
+	if models.IsTerminalBookingStatus(booking.Status) {
+		return nil, fmt.Errorf("booking %d is %s: %w", bookingId, booking.Status, apperrors.BookingClosed)
+	}
+
+	if err = validateBookingWindow(s.settingService, req.Start, req.End); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err = s.carparkService.checkOpeningHours(ctx, booking.CarparkId, req.Start, req.End); err != nil {
+		return nil, err
+	}
+
+	start := req.Start.UTC()
+	end := req.End.UTC()
+
+	// the new window is priced as of now, the promo discount taken at booking still applies
+	prices, err := s.agreedPrices(ctx, booking.VehicleId, []dtos.TimeWindow{{Start: start, End: end}})
+	if err != nil {
+		return nil, err
+	}
+	price := max(prices[0]-booking.PromoDiscount, 0)
+
+	// 1. Only move the booking if its status did not change since it was read, a booking cancelled
+	// in the meantime must not take its slot back. The booking and its schedule move in one transaction.
+	filter := bson.M{"_id": bookingId, "status": booking.Status}
+	update := bson.M{
+		"$set": bson.M{
+			"start": start,
+			"end":   end,
+			"price": price,
+		},
+	}
+
+	session, err := s.client.StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		result, err := s.coll.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update booking %d: %w", bookingId, err)
+		}
+		if result.MatchedCount == 0 {
+			return nil, fmt.Errorf("booking %d changed while rescheduling: %w", bookingId, apperrors.InvalidStatusTransition)
+		}
+
+		// 2. Move the vehicle schedule, this rejects overlaps with other bookings
+		return nil, s.carparkService.moveSchedule(ctx, booking.CarparkId, booking.VehicleId, bookingId, start, end)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	booking.Start = start
+	booking.End = end
+	booking.Price = &price
+	return booking, nil
+}
+
+// CreateRecurringBooking books every occurrence of the rule on one vehicle, all or nothing.
+// When any occurrence cannot be booked nothing is written and the conflicts are returned instead.
+func (s *BookingService) CreateRecurringBooking(req dtos.CreateRecurringBookingRequest) (*dtos.RecurringBookingResponse, error) {
+	// 1. Expand the rule into windows
+	windows, err := expandOccurrences(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(windows) == 0 {
+		return nil, &apperrors.ValidationError{Fields: map[string][]string{"byDay": {"No occurrence matches the rule"}}}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 2. Check every occurrence up front so all conflicts are reported at once
+	conflicts, buffer, err := s.seriesConflicts(ctx, req.CarparkId, req.VehicleId, windows)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return &dtos.RecurringBookingResponse{Bookings: []models.Booking{}, Conflicts: conflicts}, nil
+	}
+
+	// 3. Reserve the ids
+	firstBookingId, err := db.NextSequenceRange(ctx, s.counters, "bookings", len(windows))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate booking ids: %w", err)
+	}
+	seriesId, err := db.NextSequence(ctx, s.counters, "series")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate series id: %w", err)
+	}
+
+	prices, err := s.agreedPrices(ctx, req.VehicleId, windows)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	bookings := make([]models.Booking, 0, len(windows))
+	schedules := make([]models.Schedule, 0, len(windows))
+	for i, window := range windows {
+		booking := models.Booking{
+			Id:        firstBookingId + i,
+			UserId:    req.UserId,
+			CarparkId: req.CarparkId,
+			VehicleId: req.VehicleId,
+			SeriesId:  seriesId,
+			Start:     window.Start,
+			End:       window.End,
+			Status:    models.BookingStatusReserved,
+			StatusHistory: []models.BookingStatusChange{
+				{Status: models.BookingStatusReserved, At: now},
+			},
+			Price:     &prices[i],
+			CreatedAt: now,
+		}
+		bookings = append(bookings, booking)
+		schedules = append(schedules, models.Schedule{
+			Type:      models.ScheduleTypeBooking,
+			BookingId: booking.Id,
+			Status:    booking.Status,
+			Start:     booking.Start,
+			End:       booking.End,
+		})
+	}
+
+	// 4. Claim all slots in one update and record the bookings in the same transaction
+	session, err := s.client.StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		if err := s.carparkService.addSchedules(ctx, req.CarparkId, req.VehicleId, schedules, buffer); err != nil {
+			return nil, err
+		}
+		if _, err := s.coll.InsertMany(ctx, bookings); err != nil {
+			return nil, fmt.Errorf("failed to save bookings: %w", err)
+		}
+		return nil, nil
+	})
+
+	// 5. If someone booked in between, report what is now in the way
+	var conflict *apperrors.ScheduleConflictError
+	if errors.As(err, &conflict) {
+		conflicts, _, err = s.seriesConflicts(ctx, req.CarparkId, req.VehicleId, windows)
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) == 0 {
+			return nil, conflict
+		}
+		return &dtos.RecurringBookingResponse{Bookings: []models.Booking{}, Conflicts: conflicts}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.RecurringBookingResponse{
+		SeriesId:  seriesId,
+		Bookings:  bookings,
+		Conflicts: []dtos.OccurrenceConflict{},
+	}, nil
+}
+
+// seriesConflicts checks each window against the booking rules, opening hours, closures,
+// the vehicle's schedules and the other windows of the series. It also returns the vehicle's buffer.
+func (s *BookingService) seriesConflicts(ctx context.Context, carparkId, vehicleId int, windows []dtos.TimeWindow) ([]dtos.OccurrenceConflict, time.Duration, error) {
+	carpark, err := s.carparkService.findCarparkById(ctx, carparkId)
+	if err != nil {
+		return nil, 0, err
+	}
+	vehicleIndex := slices.IndexFunc(carpark.Vehicles, func(vehicle models.Vehicle) bool {
+		return vehicle.Id == vehicleId
+	})
+	if vehicleIndex < 0 {
+		return nil, 0, fmt.Errorf("vehicle %d in carpark %d: %w", vehicleId, carparkId, apperrors.VehicleNotFound)
+	}
+	vehicle := &carpark.Vehicles[vehicleIndex]
+
+	buffers, err := s.carparkService.loadBufferRules()
+	if err != nil {
+		return nil, 0, err
+	}
+	buffer := buffers.forVehicle(carpark, vehicle)
+
+	rules, err := loadBookingRules(s.settingService)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return occurrenceConflicts(carpark, vehicle, buffer, rules, windows, time.Now()), buffer, nil
+}
+
+// occurrenceConflicts checks every window against the booking rules, the carpark and the vehicle's schedules,
+// and against the previous occurrence of the series itself
+func occurrenceConflicts(carpark *models.Carpark, vehicle *models.Vehicle, buffer time.Duration, rules bookingRules, windows []dtos.TimeWindow, now time.Time) []dtos.OccurrenceConflict {
+	conflicts := []dtos.OccurrenceConflict{}
+	for i, window := range windows {
+		conflict := dtos.OccurrenceConflict{Start: window.Start, End: window.End}
+
+		var validation *apperrors.ValidationError
+		if err := rules.validate(window.Start, window.End, now); errors.As(err, &validation) {
+			conflict.Reason = validation.Error()
+			conflicts = append(conflicts, conflict)
+			continue
+		}
+
+		if !carpark.OpeningHours.IsOpenAt(window.Start) || !carpark.OpeningHours.IsOpenAt(window.End) {
+			conflict.Reason = "outside opening hours"
+			conflicts = append(conflicts, conflict)
+			continue
+		}
+
+		if slices.ContainsFunc(carpark.Closures, func(closure models.Closure) bool {
+			return closure.Overlaps(window.Start, window.End)
+		}) {
+			conflict.Reason = apperrors.CarparkClosed.Error()
+			conflicts = append(conflicts, conflict)
+			continue
+		}
+
+		padStart := window.Start.Add(-buffer)
+		padEnd := window.End.Add(buffer)
+		scheduleIndex := slices.IndexFunc(vehicle.Schedules, func(schedule models.Schedule) bool {
+			return schedule.Blocks() && schedule.Overlaps(padStart, padEnd)
+		})
+		if scheduleIndex >= 0 {
+			schedule := vehicle.Schedules[scheduleIndex]
+			conflict.Reason = (&apperrors.ScheduleConflictError{BookingId: schedule.BookingId, BlockId: schedule.BlockId}).Error()
+			conflict.BookingId = schedule.BookingId
+			conflict.BlockId = schedule.BlockId
+			conflicts = append(conflicts, conflict)
+			continue
+		}
+
+		// windows are in order so only the previous one can run into this one
+		if i > 0 && windows[i-1].End.Add(buffer).After(window.Start) {
+			conflict.Reason = "overlaps the previous occurrence"
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	return conflicts
+}
+
+// CancelSeries cancels every booking of the series that has not started yet, all of them or none
+func (s *BookingService) CancelSeries(seriesId int) ([]models.Booking, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"start": 1})
+	cursor, err := s.coll.Find(ctx, bson.M{"seriesId": seriesId}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find series %d: %w", seriesId, err)
+	}
+	defer cursor.Close(ctx)
+
+	var series []models.Booking
+	if err := cursor.All(ctx, &series); err != nil {
+		return nil, fmt.Errorf("decoding failed: %w", err)
+	}
+	if len(series) == 0 {
+		return nil, fmt.Errorf("series %d: %w", seriesId, apperrors.BookingNotFound)
+	}
+
+	return s.cancelReserved(ctx, series)
+}
+
+// cancelReserved cancels every reserved booking of a group or series in one transaction,
+// the freed carparks are told once the cancellation is committed
+func (s *BookingService) cancelReserved(ctx context.Context, bookings []models.Booking) ([]models.Booking, error) {
+	session, err := s.client.StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	var cancelled []models.Booking
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		cancelled = []models.Booking{}
+		change := models.BookingStatusChange{Status: models.BookingStatusCancelled, At: time.Now().UTC()}
+
+		for _, booking := range bookings {
+			if booking.Status != models.BookingStatusReserved {
+				continue
+			}
+
+			// 1. Only cancel bookings still reserved, a booking that moved on aborts all of them
+			fee, err := s.bookingFee(ctx, &booking, change.Status, change.At)
+			if err != nil {
+				return nil, err
+			}
+			set := bson.M{"status": change.Status}
+			if fee != nil {
+				set["fee"] = fee
+			}
+			filter := bson.M{"_id": booking.Id, "status": models.BookingStatusReserved}
+			update := bson.M{
+				"$set":  set,
+				"$push": bson.M{"statusHistory": change},
+			}
+			result, err := s.coll.UpdateOne(ctx, filter, update)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update booking %d: %w", booking.Id, err)
+			}
+			if result.MatchedCount == 0 {
+				return nil, fmt.Errorf("booking %d changed while cancelling: %w", booking.Id, apperrors.InvalidStatusTransition)
+			}
+
+			// 2. Free the vehicle in the same transaction
+			if err := s.carparkService.updateScheduleStatus(ctx, booking.CarparkId, booking.VehicleId, booking.Id, change.Status); err != nil {
+				return nil, err
+			}
+
+			booking.Status = change.Status
+			booking.StatusHistory = append(booking.StatusHistory, change)
+			booking.Fee = fee
+			cancelled = append(cancelled, booking)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Announce the freed vehicles only now that the cancellation is committed
+	notified := make(map[int]bool)
+	for _, booking := range cancelled {
+		if !notified[booking.CarparkId] {
+			s.carparkService.availabilityChanged(booking.CarparkId)
+			notified[booking.CarparkId] = true
+		}
+	}
+
+	return cancelled, nil
+}
+
+func (s *BookingService) GetBooking(bookingId int) (*models.Booking, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var booking models.Booking
+	err := s.coll.FindOne(ctx, bson.M{"_id": bookingId}).Decode(&booking)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("booking %d: %w", bookingId, apperrors.BookingNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find booking %d: %w", bookingId, err)
+	}
+
+	return &booking, nil
+}
+
+func (s *BookingService) GetUserBookings(userId int) ([]models.Booking, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"start": -1})
+	cursor, err := s.coll.Find(ctx, bson.M{"userId": userId}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bookings of user %d: %w", userId, err)
+	}
+	defer cursor.Close(ctx)
+
+	bookings := []models.Booking{}
+	if err := cursor.All(ctx, &bookings); err != nil {
+		return nil, fmt.Errorf("decoding failed: %w", err)
+	}
+
+	return bookings, nil
+}
+
+// findOverlapping returns the bookings of a carpark that still hold a vehicle during [start, end)
+func (s *BookingService) findOverlapping(ctx context.Context, carparkId int, start, end time.Time) ([]models.Booking, error) {
+	filter := scheduleOverlap(start, end)
+	filter["carparkId"] = carparkId
+
+	opts := options.Find().SetSort(bson.M{"start": 1})
+	cursor, err := s.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bookings of carpark %d: %w", carparkId, err)
+	}
+	defer cursor.Close(ctx)
+
+	bookings := []models.Booking{}
+	if err := cursor.All(ctx, &bookings); err != nil {
+		return nil, fmt.Errorf("decoding failed: %w", err)
+	}
+
+	return bookings, nil
+}
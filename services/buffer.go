@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"example/golang-learn/models"
+	apperrors "example/golang-learn/utilities/errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// bufferRules resolves the turnaround buffer of a vehicle:
+// the price group override first, then the carpark override, then the global setting
+type bufferRules struct {
+	globalMinutes     int
+	priceGroupMinutes map[int]int
+}
+
+func (s *CarparkService) loadBufferRules() (bufferRules, error) {
+	globalMinutes, err := s.settingService.GetInt(SettingBufferMinutes, 0)
+	if err != nil {
+		return bufferRules{}, fmt.Errorf("failed to read buffer: %w", err)
+	}
+
+	// mongo documents only have string keys so the price group ids are stored as strings
+	var byPriceGroup map[string]int
+	if _, err = s.settingService.Get(SettingPriceGroupBufferMinutes, &byPriceGroup); err != nil {
+		return bufferRules{}, fmt.Errorf("failed to read price group buffers: %w", err)
+	}
+
+	rules := bufferRules{
+		globalMinutes:     globalMinutes,
+		priceGroupMinutes: make(map[int]int),
+	}
+	for key, minutes := range byPriceGroup {
+		priceGroupId, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		rules.priceGroupMinutes[priceGroupId] = minutes
+	}
+
+	return rules, nil
+}
+
+func (r bufferRules) forVehicle(carpark *models.Carpark, vehicle *models.Vehicle) time.Duration {
+	if minutes, ok := r.priceGroupMinutes[vehicle.PriceGroupId]; ok {
+		return time.Duration(minutes) * time.Minute
+	}
+	if carpark.BufferMinutes != nil {
+		return time.Duration(*carpark.BufferMinutes) * time.Minute
+	}
+	return time.Duration(r.globalMinutes) * time.Minute
+}
+
+// millisExpr is the same resolution as forVehicle as an aggregation expression,
+// evaluated for vehicle $$v inside a carpark document
+func (r bufferRules) millisExpr() any {
+	minutes := any(bson.D{{Key: "$ifNull", Value: bson.A{"$bufferMinutes", r.globalMinutes}}})
+
+	if len(r.priceGroupMinutes) > 0 {
+		branches := bson.A{}
+		for priceGroupId, groupMinutes := range r.priceGroupMinutes {
+			branches = append(branches, bson.D{
+				{Key: "case", Value: bson.D{{Key: "$eq", Value: bson.A{"$$v.priceGroupId", priceGroupId}}}},
+				{Key: "then", Value: groupMinutes},
+			})
+		}
+		minutes = bson.D{{Key: "$switch", Value: bson.D{
+			{Key: "branches", Value: branches},
+			{Key: "default", Value: minutes},
+		}}}
+	}
+
+	return bson.D{{Key: "$multiply", Value: bson.A{minutes, 60000}}}
+}
+
+// vehicleBuffer loads the buffer that applies to a single vehicle
+func (s *CarparkService) vehicleBuffer(ctx context.Context, carparkId, vehicleId int) (time.Duration, error) {
+	carpark, err := s.findCarparkById(ctx, carparkId)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range carpark.Vehicles {
+		if carpark.Vehicles[i].Id != vehicleId {
+			continue
+		}
+
+		rules, err := s.loadBufferRules()
+		if err != nil {
+			return 0, err
+		}
+		return rules.forVehicle(carpark, &carpark.Vehicles[i]), nil
+	}
+
+	return 0, fmt.Errorf("vehicle %d in carpark %d: %w", vehicleId, carparkId, apperrors.VehicleNotFound)
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	apperrors "example/golang-learn/utilities/errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SettingCancellationPolicy holds a models.CancellationPolicy document
+const SettingCancellationPolicy = "CancellationPolicy"
+
+func (s *BookingService) GetCancellationPolicy() (*models.CancellationPolicy, error) {
+	return readCancellationPolicy(s.settingService.Get)
+}
+
+// readCancellationPolicy decodes into a zero value, the decoder reuses the backing array of a slice it
+// decodes into, so starting from models.DefaultCancellationPolicy would overwrite the default's tiers
+func readCancellationPolicy(get func(key string, out any) (bool, error)) (*models.CancellationPolicy, error) {
+	var policy models.CancellationPolicy
+	found, err := get(SettingCancellationPolicy, &policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cancellation policy: %w", err)
+	}
+	if !found {
+		policy = models.DefaultCancellationPolicy.Clone()
+	}
+	return &policy, nil
+}
+
+func (s *BookingService) SetCancellationPolicy(policy models.CancellationPolicy) (*models.CancellationPolicy, error) {
+	// 1. Validate
+	fields := make(map[string][]string)
+	if policy.FreeHours < 0 {
+		fields["freeHours"] = append(fields["freeHours"], "FreeHours cannot be negative")
+	}
+	for i, tier := range append(policy.Tiers, policy.NoShow) {
+		key := "noShow"
+		if i < len(policy.Tiers) {
+			key = fmt.Sprintf("tiers[%d]", i)
+		}
+		if tier.MinHoursBefore < 0 || tier.FeePercent < 0 || tier.FeePercent > 100 || tier.FeeFixed < 0 {
+			fields[key] = append(fields[key], "Hours and fees cannot be negative, feePercent is at most 100")
+		}
+		// a tier at or past FreeHours could never charge, cancelling that early is free
+		if i < len(policy.Tiers) && tier.MinHoursBefore >= policy.FreeHours {
+			fields[key] = append(fields[key], "MinHoursBefore must be below freeHours")
+		}
+	}
+	// every moment inside FreeHours has to fall into a tier, otherwise a late cancellation would be free
+	if policy.FreeHours > 0 && !slices.ContainsFunc(policy.Tiers, func(tier models.CancellationTier) bool {
+		return tier.MinHoursBefore == 0
+	}) {
+		fields["tiers"] = append(fields["tiers"], "A tier with minHoursBefore 0 is required when freeHours is above 0")
+	}
+	if len(fields) > 0 {
+		return nil, &apperrors.ValidationError{Fields: fields}
+	}
+
+	// 2. Save it on the settings document, the next cancellation uses it
+	if err := s.settingService.Set(SettingCancellationPolicy, policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// bookingFee assesses the fee for moving the booking to status, nil when the change costs nothing.
+// Only reserved bookings are charged, a hold that is let go was never paid for.
+func (s *BookingService) bookingFee(ctx context.Context, booking *models.Booking, status string, now time.Time) (*models.BookingFee, error) {
+	if booking.Status != models.BookingStatusReserved ||
+		(status != models.BookingStatusCancelled && status != models.BookingStatusNoShow) {
+		return nil, nil
+	}
+
+	policy, err := s.GetCancellationPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	var price float64
+	if booking.Price != nil {
+		price = *booking.Price
+	} else if price, err = s.bookingPrice(ctx, booking); err != nil {
+		return nil, err
+	}
+
+	fee := &models.BookingFee{Price: price, AssessedAt: now}
+	if status == models.BookingStatusNoShow {
+		fee.Reason = models.FeeReasonNoShow
+		fee.Amount = policy.NoShowFee(price)
+	} else {
+		fee.Reason = models.FeeReasonCancellation
+		fee.Amount = policy.CancellationFee(price, booking.Start.Sub(now))
+	}
+	return fee, nil
+}
+
+// agreedPrices prices the windows as they are booked, the price is saved on the booking
+// so a later change to the tariff or the vehicle discount does not move its cancellation fee.
+// A vehicle without a tariff prices at 0, so only fixed fees apply.
+func (s *BookingService) agreedPrices(ctx context.Context, vehicleId int, windows []dtos.TimeWindow) ([]float64, error) {
+	prices, err := s.pricingService.windowPrices(ctx, vehicleId, windows)
+	if errors.Is(err, apperrors.PriceGroupNotFound) {
+		log.Warn().Err(err).Int("vehicleId", vehicleId).Msg("vehicle has no tariff, booking is priced at 0")
+		return make([]float64, len(windows)), nil
+	}
+	return prices, err
+}
+
+// bookingPrice is what the booking costs today minus its promo discount, for bookings saved before
+// the agreed price was recorded. A vehicle that was removed or has no tariff prices at 0, so only fixed fees apply.
+func (s *BookingService) bookingPrice(ctx context.Context, booking *models.Booking) (float64, error) {
+	quote, _, err := s.pricingService.quote(ctx, dtos.QuoteRequest{
+		VehicleId: booking.VehicleId,
+		Start:     booking.Start,
+		End:       booking.End,
+	})
+	if errors.Is(err, apperrors.VehicleNotFound) || errors.Is(err, apperrors.PriceGroupNotFound) {
+		log.Warn().Err(err).Int("bookingId", booking.Id).Msg("booking has no price, only fixed fees apply")
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return roundCents(max(quote.Total-booking.PromoDiscount, 0)), nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"example/golang-learn/models"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// settingsGetter decodes keys out of a settings document the way SettingService.Get does
+func settingsGetter(t *testing.T, settings bson.M) func(key string, out any) (bool, error) {
+	t.Helper()
+	raw, err := bson.Marshal(settings)
+	if err != nil {
+		t.Fatalf("bad settings %v: %v", settings, err)
+	}
+	return func(key string, out any) (bool, error) {
+		val, err := bson.Raw(raw).LookupErr(key)
+		if err != nil {
+			return false, nil
+		}
+		return true, val.Unmarshal(out)
+	}
+}
+
+func TestReadCancellationPolicy(t *testing.T) {
+	defaultTiers := slices.Clone(models.DefaultCancellationPolicy.Tiers)
+
+	tests := []struct {
+		name      string
+		settings  bson.M
+		wantTiers []models.CancellationTier
+	}{
+		{
+			name:      "nothing saved uses the default",
+			settings:  bson.M{},
+			wantTiers: defaultTiers,
+		},
+		{
+			name: "saved policy",
+			settings: bson.M{SettingCancellationPolicy: models.CancellationPolicy{
+				FreeHours: 12,
+				Tiers:     []models.CancellationTier{{MinHoursBefore: 0, FeePercent: 80}},
+			}},
+			wantTiers: []models.CancellationTier{{MinHoursBefore: 0, FeePercent: 80}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := readCancellationPolicy(settingsGetter(t, tt.settings))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(policy.Tiers, tt.wantTiers) {
+				t.Errorf("got tiers %v, want %v", policy.Tiers, tt.wantTiers)
+			}
+
+			// neither decoding nor changing the returned policy may touch the default
+			if len(policy.Tiers) > 0 {
+				policy.Tiers[0].FeePercent = 99
+			}
+			if !slices.Equal(models.DefaultCancellationPolicy.Tiers, defaultTiers) {
+				t.Errorf("default tiers changed to %v, want %v", models.DefaultCancellationPolicy.Tiers, defaultTiers)
+			}
+		})
+	}
+}
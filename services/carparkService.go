@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"example/golang-learn/dtos"
 	"example/golang-learn/models"
+	apperrors "example/golang-learn/utilities/errors"
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"sort"
 	"strconv"
 	"time"
 
@@ -18,17 +22,44 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+// SortBy values of the carpark search
+const (
+	SortByDistance = "distance"
+	SortBySlashed  = "slashed"
+)
+
 type CarparkService struct {
-	coll *mongo.Collection
+	coll           *mongo.Collection
+	priceGroups    *mongo.Collection
+	settingService *SettingService
+	// availabilityListeners are called with a carpark id after something was freed up or added in it
+	availabilityListeners []func(carparkId int)
 }
 
-func NewCarparkService(coll *mongo.Collection) *CarparkService {
+func NewCarparkService(coll *mongo.Collection, priceGroups *mongo.Collection, settingService *SettingService) *CarparkService {
 	return &CarparkService{
-		coll: coll,
+		coll:           coll,
+		priceGroups:    priceGroups,
+		settingService: settingService,
+	}
+}
+
+// AddAvailabilityListener registers fn to run in the background whenever a vehicle may have become available
+func (s *CarparkService) AddAvailabilityListener(fn func(carparkId int)) {
+	s.availabilityListeners = append(s.availabilityListeners, fn)
+}
+
+func (s *CarparkService) availabilityChanged(carparkId int) {
+	for _, fn := range s.availabilityListeners {
+		go fn(carparkId)
 	}
 }
 
 func (s *CarparkService) AddCarpark(newCarpark *models.Carpark) error {
+	if err := validateCarpark(newCarpark); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -58,6 +89,82 @@ func (s *CarparkService) AddCarpark(newCarpark *models.Carpark) error {
 	return err
 }
 
+func validateCarpark(carpark *models.Carpark) error {
+	fields := make(map[string][]string)
+	if carpark.BufferMinutes != nil && *carpark.BufferMinutes < 0 {
+		fields["bufferMinutes"] = append(fields["bufferMinutes"], "Buffer cannot be negative")
+	}
+	if carpark.OpeningHours != nil {
+		validateOpeningTimes(carpark.OpeningHours, fields)
+	}
+
+	if len(fields) > 0 {
+		return &apperrors.ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// validateOpeningTimes checks the clocks are real "HH:MM" times and the dates exist.
+// A close before the open is allowed, those hours run past midnight.
+func validateOpeningTimes(openingTimes *models.OpeningTimes, fields map[string][]string) {
+	validSpan := func(open, close string) bool {
+		openMinutes, err := models.ParseClock(open)
+		if err != nil {
+			return false
+		}
+		closeMinutes, err := models.ParseClock(close)
+		return err == nil && closeMinutes != openMinutes
+	}
+
+	for i, hours := range openingTimes.Weekly {
+		key := fmt.Sprintf("openingHours.weekly[%d]", i)
+		if hours.Weekday < time.Sunday || hours.Weekday > time.Saturday {
+			fields[key] = append(fields[key], "Weekday must be 0 (Sunday) to 6 (Saturday)")
+		}
+		if !validSpan(hours.Open, hours.Close) {
+			fields[key] = append(fields[key], "Open and close must be different HH:MM times, 24:00 is midnight")
+		}
+	}
+
+	for i, exception := range openingTimes.Exceptions {
+		key := fmt.Sprintf("openingHours.exceptions[%d]", i)
+		if _, err := time.Parse(time.DateOnly, exception.Date); err != nil {
+			fields[key] = append(fields[key], "Date must be YYYY-MM-DD")
+		}
+		if !exception.Closed && !validSpan(exception.Open, exception.Close) {
+			fields[key] = append(fields[key], "Open and close must be different HH:MM times unless the carpark is closed")
+		}
+	}
+}
+
+// UpdateBuffer overrides the turnaround buffer of every vehicle in the carpark, nil falls back to the global setting.
+// Existing bookings are kept even if they are now closer together than the new buffer.
+func (s *CarparkService) UpdateBuffer(carparkId int, req dtos.UpdateCarparkBufferRequest) error {
+	if req.BufferMinutes != nil && *req.BufferMinutes < 0 {
+		return &apperrors.ValidationError{Fields: map[string][]string{"bufferMinutes": {"Buffer cannot be negative"}}}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$unset": bson.M{"bufferMinutes": ""}}
+	if req.BufferMinutes != nil {
+		update = bson.M{"$set": bson.M{"bufferMinutes": *req.BufferMinutes}}
+	}
+
+	result, err := s.coll.UpdateOne(ctx, bson.M{"_id": carparkId}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update buffer of carpark %d: %w", carparkId, err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("carpark %d: %w", carparkId, apperrors.CarparkNotFound)
+	}
+
+	// a shorter buffer can free up slots
+	s.availabilityChanged(carparkId)
+	return nil
+}
+
 func (s *CarparkService) UpdatePostalCode(carparkName string, postalCode string) error {
 	filter := bson.M{"name": carparkName}
 
@@ -87,54 +194,188 @@ func (s *CarparkService) GetCarpark(postalCode string) (*models.Carpark, error)
 	return &carpark, nil
 }
 
-func (s *CarparkService) GetAvailableVehicles(lon, lat float64, start, end time.Time) ([]models.Carpark, error) {
+// GetAvailableVehicles also returns the radius that was finally searched, which is larger than requested when expand was on
+func (s *CarparkService) GetAvailableVehicles(req dtos.CarparksRequest) ([]dtos.CarparkResult, float64, error) {
+	if req.SortBy != "" && req.SortBy != SortByDistance && req.SortBy != SortBySlashed {
+		return nil, 0, &apperrors.ValidationError{Fields: map[string][]string{"sortBy": {"SortBy must be distance or slashed"}}}
+	}
+
+	radiusKm, err := s.searchRadiusKm(req.RadiusKm)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	buffers, err := s.loadBufferRules()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ceilingKm := radiusKm
+	if req.Expand {
+		expandMaxKm, err := s.settingService.GetInt(SettingExpandMaxRadiusKm, 50)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read expand max radius: %w", err)
+		}
+		ceilingKm = max(radiusKm, float64(expandMaxKm))
+	}
+
+	// Double the radius until something is bookable or the ceiling is reached
+	for {
+		results, err := s.searchCarparks(req, radiusKm, buffers)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if radiusKm >= ceilingKm || hasAvailableVehicles(results) {
+			return results, radiusKm, nil
+		}
+
+		radiusKm = min(max(radiusKm*2, 1), ceilingKm)
+	}
+}
+
+func hasAvailableVehicles(results []dtos.CarparkResult) bool {
+	for _, result := range results {
+		if result.AvailableVehicles > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *CarparkService) searchCarparks(req dtos.CarparksRequest, radiusKm float64, buffers bufferRules) ([]dtos.CarparkResult, error) {
 	// 1. Ensure UTC for MongoDB compatibility
-	start = start.UTC()
-	end = end.UTC()
+	start := req.Start.UTC()
+	end := req.End.UTC()
+
+	// 2. Build the per-vehicle conditions, a vehicle is counted only if it matches every filter
+	vehicleConds := bson.A{
+		bson.D{{Key: "$let", Value: bson.D{
+			// Turnaround buffer of this vehicle, kept free on both sides of the requested window
+			{Key: "vars", Value: bson.D{{Key: "bufferMs", Value: buffers.millisExpr()}}},
+			{Key: "in", Value: bson.D{{Key: "$eq", Value: bson.A{
+				bson.D{{Key: "$size", Value: bson.D{
+					{Key: "$filter", Value: bson.D{
+						// Note: Your data uses "schedules" (plural)
+						{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$$v.schedules", bson.A{}}}}},
+						{Key: "as", Value: "sch"},
+						{Key: "cond", Value: bson.D{
+							{Key: "$and", Value: bson.A{
+								// Overlap: existing.start < requested.end + buffer AND existing.end > requested.start - buffer
+								bson.D{{Key: "$lt", Value: bson.A{"$$sch.start", bson.D{{Key: "$add", Value: bson.A{end, "$$bufferMs"}}}}}},
+								bson.D{{Key: "$gt", Value: bson.A{"$$sch.end", bson.D{{Key: "$subtract", Value: bson.A{start, "$$bufferMs"}}}}}},
+								// Completed, cancelled and no-show bookings no longer hold the vehicle
+								bson.D{{Key: "$not", Value: bson.A{
+									bson.D{{Key: "$in", Value: bson.A{
+										bson.D{{Key: "$ifNull", Value: bson.A{"$$sch.status", ""}}},
+										models.TerminalBookingStatuses,
+									}}},
+								}}},
+								// Neither do holds that have expired
+								bson.D{{Key: "$or", Value: bson.A{
+									bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$$sch.expiresAt", nil}}}, nil}}},
+									bson.D{{Key: "$gt", Value: bson.A{"$$sch.expiresAt", time.Now()}}},
+								}}},
+							}},
+						}},
+					}},
+				}}},
+				0, // No overlapping schedules means vehicle is available
+			}}}},
+		}}},
+	}
+	// Every vehicle of a carpark that is closed during the window is unavailable
+	vehicleConds = append(vehicleConds, bson.D{{Key: "$eq", Value: bson.A{
+		bson.D{{Key: "$size", Value: bson.D{
+			{Key: "$filter", Value: bson.D{
+				{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$closures", bson.A{}}}}},
+				{Key: "as", Value: "c"},
+				{Key: "cond", Value: bson.D{
+					{Key: "$and", Value: bson.A{
+						bson.D{{Key: "$lt", Value: bson.A{"$$c.start", end}}},
+						bson.D{{Key: "$gt", Value: bson.A{"$$c.end", start}}},
+					}},
+				}},
+			}},
+		}}},
+		0,
+	}}})
+	if len(req.PriceGroupIds) > 0 {
+		vehicleConds = append(vehicleConds, bson.D{{Key: "$in", Value: bson.A{"$$v.priceGroupId", req.PriceGroupIds}}})
+	}
+	if len(req.VehicleTypeIds) > 0 {
+		vehicleConds = append(vehicleConds, bson.D{{Key: "$in", Value: bson.A{"$$v.vehicleTypeId", req.VehicleTypeIds}}})
+	}
+	if req.NumSeats > 0 {
+		vehicleConds = append(vehicleConds, bson.D{{Key: "$gte", Value: bson.A{"$$v.seats", req.NumSeats}}})
+	}
+	if req.SlashedOnly {
+		vehicleConds = append(vehicleConds, bson.D{{Key: "$gt", Value: bson.A{
+			bson.D{{Key: "$size", Value: coveringDiscounts(start, end)}},
+			0,
+		}}})
+	}
 
 	pipeline := mongo.Pipeline{
-		// Stage 1: Geospatial search (20km radius)
+		// Stage 1: Geospatial search within the radius
 		{{Key: "$geoNear", Value: bson.D{
 			{Key: "near", Value: bson.D{
 				{Key: "type", Value: "Point"},
-				{Key: "coordinates", Value: []float64{lon, lat}},
+				{Key: "coordinates", Value: []float64{req.Longitude, req.Latitude}},
 			}},
 			{Key: "distanceField", Value: "dist"},
 			{Key: "spherical", Value: true},
-			{Key: "maxDistance", Value: 20000},
+			{Key: "maxDistance", Value: radiusKm * 1000},
 		}}},
 
-		// Stage 2: Calculate availability
-		{{Key: "$addFields", Value: bson.D{
-			{Key: "availableVehicles", Value: bson.D{
-				{Key: "$size", Value: bson.D{
-					{Key: "$filter", Value: bson.D{
-						// Handle potential null vehicles array
-						{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$vehicles", bson.A{}}}}},
-						{Key: "as", Value: "v"},
-						{Key: "cond", Value: bson.D{
-							{Key: "$eq", Value: bson.A{
-								bson.D{{Key: "$size", Value: bson.D{
-									{Key: "$filter", Value: bson.D{
-										// Note: Your data uses "schedules" (plural)
-										{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$$v.schedules", bson.A{}}}}},
-										{Key: "as", Value: "sch"},
-										{Key: "cond", Value: bson.D{
-											{Key: "$and", Value: bson.A{
-												// Overlap: existing.start < requested.end AND existing.end > requested.start
-												bson.D{{Key: "$lt", Value: bson.A{"$$sch.start", end}}},
-												bson.D{{Key: "$gt", Value: bson.A{"$$sch.end", start}}},
-											}},
-										}},
-									}},
-								}}},
-								0, // No overlapping schedules means vehicle is available
-							}},
+		// Stage 2: Keep only the vehicles that are free for the window, trimmed to the public fields
+		{{Key: "$project", Value: bson.D{
+			{Key: "name", Value: 1},
+			{Key: "postalCode", Value: 1},
+			{Key: "address", Value: 1},
+			{Key: "location", Value: 1},
+			{Key: "openingHours", Value: 1},
+			{Key: "dist", Value: 1},
+			// Handle potential null vehicles array
+			{Key: "totalVehicles", Value: bson.D{{Key: "$size", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$vehicles", bson.A{}}}}}}},
+			{Key: "vehicles", Value: bson.D{
+				{Key: "$map", Value: bson.D{
+					{Key: "input", Value: bson.D{
+						{Key: "$filter", Value: bson.D{
+							{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$vehicles", bson.A{}}}}},
+							{Key: "as", Value: "v"},
+							{Key: "cond", Value: bson.D{{Key: "$and", Value: vehicleConds}}},
 						}},
 					}},
-				}}},
+					{Key: "as", Value: "v"},
+					{Key: "in", Value: bson.D{
+						{Key: "_id", Value: "$$v._id"},
+						{Key: "makeName", Value: "$$v.makeName"},
+						{Key: "modelName", Value: "$$v.modelName"},
+						{Key: "seats", Value: "$$v.seats"},
+						{Key: "vehicleTypeId", Value: "$$v.vehicleTypeId"},
+						{Key: "priceGroupId", Value: "$$v.priceGroupId"},
+						{Key: "priceGroupName", Value: "$$v.priceGroupName"},
+						{Key: "lots", Value: "$$v.lots"},
+						{Key: "images", Value: "$$v.images"},
+						{Key: "slashed", Value: bson.D{{Key: "$first", Value: coveringDiscounts(start, end)}}},
+					}},
+				}},
 			}},
-		}}}
+		}}},
+
+		// Stage 3: Count what is left and flag carparks where one of those vehicles is discounted
+		{{Key: "$addFields", Value: bson.D{
+			{Key: "availableVehicles", Value: bson.D{{Key: "$size", Value: "$vehicles"}}},
+			{Key: "hasSlashedVehicle", Value: bson.D{{Key: "$anyElementTrue", Value: bson.A{
+				bson.D{{Key: "$map", Value: bson.D{
+					{Key: "input", Value: "$vehicles"},
+					{Key: "as", Value: "v"},
+					{Key: "in", Value: bson.D{{Key: "$ne", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$$v.slashed", nil}}}, nil}}}},
+				}}},
+			}}}},
+		}}},
+	}
 
 	cursor, err := s.coll.Aggregate(context.TODO(), pipeline)
 	if err != nil {
@@ -142,14 +383,200 @@ func (s *CarparkService) GetAvailableVehicles(lon, lat float64, start, end time.
 	}
 	defer cursor.Close(context.TODO())
 
-	var results []models.Carpark
+	var results []dtos.CarparkResult
 	if err := cursor.All(context.TODO(), &results); err != nil {
 		return nil, fmt.Errorf("decoding failed: %w", err)
 	}
 
+	// Pick-up and return both have to fall inside the opening hours, otherwise nothing is bookable there
+	for i := range results {
+		if !results[i].OpeningHours.IsOpenAt(start) || !results[i].OpeningHours.IsOpenAt(end) {
+			results[i].Vehicles = []dtos.AvailableVehicle{}
+			results[i].AvailableVehicles = 0
+			results[i].HasSlashedVehicle = false
+		}
+	}
+
+	// Discounted carparks first, $geoNear already sorted by distance so the stable sort keeps the nearest first
+	if req.SortBy == SortBySlashed {
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].HasSlashedVehicle && !results[j].HasSlashedVehicle
+		})
+	}
+
 	return results, nil
 }
 
+// coveringDiscounts is the $filter of the vehicle's discounts that cover the whole window, see models.Discount
+func coveringDiscounts(start, end time.Time) bson.D {
+	return bson.D{{Key: "$filter", Value: bson.D{
+		{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$$v.discounts", bson.A{}}}}},
+		{Key: "as", Value: "d"},
+		{Key: "cond", Value: bson.D{{Key: "$and", Value: bson.A{
+			bson.D{{Key: "$lte", Value: bson.A{"$$d.start", start}}},
+			bson.D{{Key: "$gte", Value: bson.A{"$$d.end", end}}},
+		}}}},
+	}}}
+}
+
+// searchRadiusKm reads the radius settings on every search so a change in the settings collection
+// applies to the next request. A requested radius overrides the default but is capped at MaxRadiusKm.
+func (s *CarparkService) searchRadiusKm(requestedKm float64) (float64, error) {
+	radiusKm, err := s.settingService.GetInt(SettingRadiusKm, 20)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read search radius: %w", err)
+	}
+
+	maxRadiusKm, err := s.settingService.GetInt(SettingMaxRadiusKm, 50)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read max search radius: %w", err)
+	}
+
+	if requestedKm <= 0 {
+		return min(float64(radiusKm), float64(maxRadiusKm)), nil
+	}
+	return min(requestedKm, float64(maxRadiusKm)), nil
+}
+
+// FindSlots returns, per vehicle, the earliest free windows inside the horizon that fit the duration
+func (s *CarparkService) FindSlots(req dtos.SlotsRequest) ([]dtos.VehicleSlots, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 1. Load the carpark, either directly or through the vehicle
+	var carpark *models.Carpark
+	var err error
+	if req.CarparkId != 0 {
+		carpark, err = s.findCarparkById(ctx, req.CarparkId)
+	} else {
+		carpark, _, err = s.findVehicle(ctx, req.VehicleId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	buffers, err := s.loadBufferRules()
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Walk the gaps between schedules of every vehicle, a slot starts and ends while the carpark is open
+	from := req.From.UTC()
+	to := req.To.UTC()
+	open := carpark.OpeningHours.OpenPeriods(from, to)
+	results := []dtos.VehicleSlots{}
+	for _, vehicle := range carpark.Vehicles {
+		if req.VehicleId != 0 && vehicle.Id != req.VehicleId {
+			continue
+		}
+
+		slots := dtos.VehicleSlots{
+			VehicleId:   vehicle.Id,
+			PlateNumber: vehicle.PlateNumber,
+			Windows:     []dtos.TimeWindow{},
+		}
+		buffer := buffers.forVehicle(carpark, &vehicle)
+		if req.Buffer != nil {
+			buffer = *req.Buffer
+		}
+		busy := busyWindows(vehicle.Schedules, carpark.Closures, from, to, buffer)
+		for _, window := range freeWindows(busy, from, to) {
+			window, ok := openWithin(open, window)
+			if !ok || window.End.Sub(window.Start) < req.Duration {
+				continue
+			}
+			slots.Windows = append(slots.Windows, window)
+			if len(slots.Windows) == req.Limit {
+				break
+			}
+		}
+		results = append(results, slots)
+	}
+
+	if req.VehicleId != 0 && len(results) == 0 {
+		return nil, fmt.Errorf("vehicle %d in carpark %d: %w", req.VehicleId, carpark.Id, apperrors.VehicleNotFound)
+	}
+
+	return results, nil
+}
+
+// GetVehicleCalendar returns the merged busy intervals of a vehicle and the gaps between them, clipped to [from, to)
+func (s *CarparkService) GetVehicleCalendar(vehicleId int, from, to time.Time) (*dtos.VehicleCalendar, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	carpark, vehicle, err := s.findVehicle(ctx, vehicleId)
+	if err != nil {
+		return nil, err
+	}
+
+	from = from.UTC()
+	to = to.UTC()
+	busy := busyWindows(vehicle.Schedules, carpark.Closures, from, to, 0)
+
+	// free time only counts from when the vehicle can be picked up until it last can be returned
+	open := carpark.OpeningHours.OpenPeriods(from, to)
+	free := []dtos.TimeWindow{}
+	for _, window := range freeWindows(busy, from, to) {
+		if window, ok := openWithin(open, window); ok {
+			free = append(free, window)
+		}
+	}
+	openWindows := make([]dtos.TimeWindow, 0, len(open))
+	for _, period := range open {
+		openWindows = append(openWindows, dtos.TimeWindow{Start: period.Start, End: period.End})
+	}
+	closed := freeWindows(openWindows, from, to)
+
+	// a booking can start before or end after the range, the week view only draws what is inside it
+	for i := range busy {
+		if busy[i].Start.Before(from) {
+			busy[i].Start = from
+		}
+		busy[i].End = minTime(busy[i].End, to)
+	}
+
+	return &dtos.VehicleCalendar{
+		VehicleId: vehicleId,
+		From:      from,
+		To:        to,
+		Busy:      append([]dtos.TimeWindow{}, busy...),
+		Free:      free,
+		Closed:    append([]dtos.TimeWindow{}, closed...),
+	}, nil
+}
+
+func (s *CarparkService) findCarparkById(ctx context.Context, carparkId int) (*models.Carpark, error) {
+	var carpark models.Carpark
+	err := s.coll.FindOne(ctx, bson.M{"_id": carparkId}).Decode(&carpark)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("carpark %d: %w", carparkId, apperrors.CarparkNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find carpark %d: %w", carparkId, err)
+	}
+	return &carpark, nil
+}
+
+// findVehicle looks a vehicle up by id, vehicle ids are unique across all carparks
+func (s *CarparkService) findVehicle(ctx context.Context, vehicleId int) (*models.Carpark, *models.Vehicle, error) {
+	var carpark models.Carpark
+	err := s.coll.FindOne(ctx, bson.M{"vehicles._id": vehicleId}).Decode(&carpark)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil, fmt.Errorf("vehicle %d: %w", vehicleId, apperrors.VehicleNotFound)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find vehicle %d: %w", vehicleId, err)
+	}
+
+	for i := range carpark.Vehicles {
+		if carpark.Vehicles[i].Id == vehicleId {
+			return &carpark, &carpark.Vehicles[i], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("vehicle %d: %w", vehicleId, apperrors.VehicleNotFound)
+}
+
 func (s *CarparkService) RemoveVehicleFromCarpark(carparkName string, plateNumber string) error {
 	// 1. Filter: Find the specific carpark
 	filter := bson.M{"name": carparkName}
@@ -205,19 +632,31 @@ func (s *CarparkService) AddVehicleToCarpark(req *dtos.AddVehicleRequest) error
 		}
 	}
 
-	// 2. Build the Vehicle object
+	// 2. The price group must exist, its name is copied from the catalog so it cannot drift
+	priceGroup, err := findPriceGroup(ctx, s.priceGroups, req.PriceGroupId)
+	if errors.Is(err, apperrors.PriceGroupNotFound) {
+		return &apperrors.ValidationError{Fields: map[string][]string{"priceGroupId": {"Unknown price group"}}}
+	}
+	if err != nil {
+		return err
+	}
+
+	// 3. Build the Vehicle object
 	vehicle := models.Vehicle{
-		Id:          newVehicleId, // Assign the incremented ID
-		MakeName:    req.MakeName,
-		ModelName:   req.ModelName,
-		PlateNumber: req.PlateNumber,
-		Seats:       req.Seats,
-		Lots:        req.Lots,
-		Images:      req.Images,
-		Schedules:   []models.Schedule{},
+		Id:             newVehicleId, // Assign the incremented ID
+		MakeName:       req.MakeName,
+		ModelName:      req.ModelName,
+		PlateNumber:    req.PlateNumber,
+		Seats:          req.Seats,
+		VehicleTypeId:  req.VehicleTypeId,
+		PriceGroupId:   priceGroup.Id,
+		PriceGroupName: priceGroup.Name,
+		Lots:           req.Lots,
+		Images:         req.Images,
+		Schedules:      []models.Schedule{},
 	}
 
-	// 3. Update the specific carpark
+	// 4. Update the specific carpark
 	filter := bson.M{"name": req.CarparkName}
 	update := bson.M{
 		"$push": bson.M{
@@ -225,15 +664,19 @@ func (s *CarparkService) AddVehicleToCarpark(req *dtos.AddVehicleRequest) error
 		},
 	}
 
-	result, err := s.coll.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return err
+	opts := options.FindOneAndUpdate().SetProjection(bson.M{"_id": 1})
+	var carpark struct {
+		Id int `bson:"_id"`
 	}
-
-	if result.MatchedCount == 0 {
+	err = s.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&carpark)
+	if err == mongo.ErrNoDocuments {
 		return fmt.Errorf("carpark '%s' not found", req.CarparkName)
 	}
+	if err != nil {
+		return err
+	}
 
+	s.availabilityChanged(carpark.Id)
 	return nil
 }
 
@@ -317,31 +760,66 @@ func (s *CarparkService) AddScheduleToVehicle(req dtos.AddScheduleRequest) error
 	// 1. Parse strings to time.Time (MongoDB needs Date objects, not strings)
 	startTime, err := time.Parse(time.RFC3339, req.Start)
 	if err != nil {
-		return fmt.Errorf("invalid start time: %w", err)
+		return &apperrors.ValidationError{Fields: map[string][]string{"start": {"Start must be an RFC3339 time"}}}
 	}
 	endTime, err := time.Parse(time.RFC3339, req.End)
 	if err != nil {
-		return fmt.Errorf("invalid end time: %w", err)
+		return &apperrors.ValidationError{Fields: map[string][]string{"end": {"End must be an RFC3339 time"}}}
 	}
 
-	// 2. Define the filter (Find the Carpark)
-	filter := bson.M{"_id": req.CarparkId}
+	// 2. Check duration, alignment, lead time and opening hours
+	if err = validateBookingWindow(s.settingService, startTime, endTime); err != nil {
+		return err
+	}
+	if err = s.checkOpeningHours(ctx, req.CarparkId, startTime, endTime); err != nil {
+		return err
+	}
+
+	schedule := models.Schedule{
+		BookingId: req.BookingId,
+		Start:     startTime.UTC(),
+		End:       endTime.UTC(),
+	}
+
+	return s.addSchedule(ctx, req.CarparkId, req.VehicleId, schedule)
+}
+
+// addSchedule pushes the schedule only if the vehicle has nothing overlapping it.
+// The overlap check is part of the update filter so it is atomic on the carpark document.
+func (s *CarparkService) addSchedule(ctx context.Context, carparkId, vehicleId int, schedule models.Schedule) error {
+	// 1. Widen the window by the vehicle's turnaround buffer
+	buffer, err := s.vehicleBuffer(ctx, carparkId, vehicleId)
+	if err != nil {
+		return err
+	}
+	start := schedule.Start.Add(-buffer)
+	end := schedule.End.Add(buffer)
+
+	// 2. Define the filter (Find the open Carpark whose vehicle has no overlapping schedule)
+	filter := bson.M{
+		"_id":      carparkId,
+		"closures": bson.M{"$not": bson.M{"$elemMatch": windowOverlap(schedule.Start, schedule.End)}},
+		"vehicles": bson.M{
+			"$elemMatch": bson.M{
+				"_id": vehicleId,
+				"schedules": bson.M{
+					"$not": bson.M{"$elemMatch": scheduleOverlap(start, end)},
+				},
+			},
+		},
+	}
 
 	// 3. Define the Update logic
 	// We use "vehicles.$[v].schedules" where [v] is a placeholder for the matched vehicle
 	update := bson.M{
 		"$push": bson.M{
-			"vehicles.$[v].schedules": bson.M{
-				"bookingId": req.BookingId,
-				"start":     startTime,
-				"end":       endTime,
-			},
+			"vehicles.$[v].schedules": schedule,
 		},
 	}
 
 	// 4. Define the ArrayFilter to identify which vehicle in the array gets the update
 	opts := options.UpdateOne().SetArrayFilters([]any{
-		bson.M{"v._id": req.VehicleId},
+		bson.M{"v._id": vehicleId},
 	})
 
 	// 5. Execute
@@ -350,33 +828,324 @@ func (s *CarparkService) AddScheduleToVehicle(req dtos.AddScheduleRequest) error
 		return fmt.Errorf("failed to update schedule: %w", err)
 	}
 
+	// 6. Nothing matched, work out whether it was a missing carpark/vehicle or a conflict
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("carpark %d or vehicle %d not found", req.CarparkId, req.VehicleId)
+		return s.scheduleNotAddedReason(ctx, carparkId, vehicleId, schedule.Start, schedule.End, buffer, 0)
 	}
 
 	return nil
 }
 
+// checkOpeningHours rejects windows whose pick-up or return is outside the carpark's opening hours
+func (s *CarparkService) checkOpeningHours(ctx context.Context, carparkId int, start, end time.Time) error {
+	carpark, err := s.findCarparkById(ctx, carparkId)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string][]string)
+	if !carpark.OpeningHours.IsOpenAt(start) {
+		fields["start"] = append(fields["start"], "Pick-up must be within the carpark opening hours")
+	}
+	if !carpark.OpeningHours.IsOpenAt(end) {
+		fields["end"] = append(fields["end"], "Return must be within the carpark opening hours")
+	}
+	if len(fields) > 0 {
+		return &apperrors.ValidationError{Fields: fields}
+	}
+
+	return nil
+}
+
+// addSchedules pushes several schedules onto one vehicle in a single update, either all of them or none.
+// Each schedule's window is widened by buffer for the overlap check, the same as addSchedule.
+func (s *CarparkService) addSchedules(ctx context.Context, carparkId, vehicleId int, schedules []models.Schedule, buffer time.Duration) error {
+	// 1. One overlap clause per new schedule, any match rejects the whole batch
+	closureOverlaps := bson.A{}
+	scheduleOverlaps := bson.A{}
+	for _, schedule := range schedules {
+		closureOverlaps = append(closureOverlaps, windowOverlap(schedule.Start, schedule.End))
+		scheduleOverlaps = append(scheduleOverlaps, scheduleOverlap(schedule.Start.Add(-buffer), schedule.End.Add(buffer)))
+	}
+
+	filter := bson.M{
+		"_id":      carparkId,
+		"closures": bson.M{"$not": bson.M{"$elemMatch": bson.M{"$or": closureOverlaps}}},
+		"vehicles": bson.M{
+			"$elemMatch": bson.M{
+				"_id": vehicleId,
+				"schedules": bson.M{
+					"$not": bson.M{"$elemMatch": bson.M{"$or": scheduleOverlaps}},
+				},
+			},
+		},
+	}
+
+	// 2. $each pushes them all in the same atomic document update
+	update := bson.M{
+		"$push": bson.M{
+			"vehicles.$[v].schedules": bson.M{"$each": schedules},
+		},
+	}
+	opts := options.UpdateOne().SetArrayFilters([]any{
+		bson.M{"v._id": vehicleId},
+	})
+
+	result, err := s.coll.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return fmt.Errorf("failed to add schedules: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return &apperrors.ScheduleConflictError{}
+	}
+
+	return nil
+}
+
+// moveSchedule changes the window of an existing booking schedule in place. Like addSchedule the overlap
+// check is in the update filter, ignoring the booking's own schedule.
+func (s *CarparkService) moveSchedule(ctx context.Context, carparkId, vehicleId, bookingId int, start, end time.Time) error {
+	// 1. The vehicle must hold this booking and nothing else overlapping the new window plus buffer
+	buffer, err := s.vehicleBuffer(ctx, carparkId, vehicleId)
+	if err != nil {
+		return err
+	}
+	otherOverlap := scheduleOverlap(start.Add(-buffer), end.Add(buffer))
+	otherOverlap["$nor"] = bson.A{bookingSchedule(bookingId)}
+
+	filter := bson.M{
+		"_id":      carparkId,
+		"closures": bson.M{"$not": bson.M{"$elemMatch": windowOverlap(start, end)}},
+		"vehicles": bson.M{
+			"$elemMatch": bson.M{
+				"_id": vehicleId,
+				"$and": bson.A{
+					bson.M{"schedules": bson.M{"$elemMatch": bookingSchedule(bookingId)}},
+					bson.M{"schedules": bson.M{"$not": bson.M{"$elemMatch": otherOverlap}}},
+				},
+			},
+		},
+	}
+
+	// 2. Set the new window on the matched schedule
+	update := bson.M{
+		"$set": bson.M{
+			"vehicles.$[v].schedules.$[sch].start": start,
+			"vehicles.$[v].schedules.$[sch].end":   end,
+		},
+	}
+	opts := options.UpdateOne().SetArrayFilters([]any{
+		bson.M{"v._id": vehicleId},
+		bookingScheduleFilter("sch", bookingId),
+	})
+
+	// 3. Execute
+	result, err := s.coll.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return fmt.Errorf("failed to move schedule: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return s.scheduleNotAddedReason(ctx, carparkId, vehicleId, start, end, buffer, bookingId)
+	}
+
+	return nil
+}
+
+// scheduleNotAddedReason explains a conditional schedule update that matched nothing.
+// ignoreBookingId is the booking being moved, it cannot conflict with itself.
+func (s *CarparkService) scheduleNotAddedReason(ctx context.Context, carparkId, vehicleId int, start, end time.Time, buffer time.Duration, ignoreBookingId int) error {
+	carpark, err := s.findCarparkById(ctx, carparkId)
+	if err != nil {
+		return err
+	}
+
+	for _, closure := range carpark.Closures {
+		if closure.Overlaps(start, end) {
+			return fmt.Errorf("carpark %d closure %d from %s to %s: %w", carparkId, closure.Id,
+				closure.Start.Format(time.RFC3339), closure.End.Format(time.RFC3339), apperrors.CarparkClosed)
+		}
+	}
+
+	// schedules conflict with the window widened by the turnaround buffer
+	start = start.Add(-buffer)
+	end = end.Add(buffer)
+
+	for _, vehicle := range carpark.Vehicles {
+		if vehicle.Id != vehicleId {
+			continue
+		}
+		found := ignoreBookingId == 0
+		for _, schedule := range vehicle.Schedules {
+			if ignoreBookingId != 0 && schedule.IsBooking() && schedule.BookingId == ignoreBookingId {
+				found = true
+				continue
+			}
+			if schedule.Blocks() && schedule.Overlaps(start, end) {
+				return &apperrors.ScheduleConflictError{BookingId: schedule.BookingId, BlockId: schedule.BlockId}
+			}
+		}
+		if !found {
+			return fmt.Errorf("schedule of booking %d on vehicle %d: %w", ignoreBookingId, vehicleId, apperrors.BookingNotFound)
+		}
+		// the conflicting schedule was removed between the update and this read
+		return &apperrors.ScheduleConflictError{}
+	}
+
+	return fmt.Errorf("vehicle %d in carpark %d: %w", vehicleId, carparkId, apperrors.VehicleNotFound)
+}
+
+// windowOverlap matches elements where existing.start < requested.end AND existing.end > requested.start
+func windowOverlap(start, end time.Time) bson.M {
+	return bson.M{
+		"start": bson.M{"$lt": end},
+		"end":   bson.M{"$gt": start},
+	}
+}
+
+// scheduleOverlap is windowOverlap limited to schedules that still block the vehicle
+func scheduleOverlap(start, end time.Time) bson.M {
+	overlap := windowOverlap(start, end)
+	overlap["status"] = bson.M{"$nin": models.TerminalBookingStatuses}
+	// expired holds stop blocking straight away, the sweeper only tidies up their status later
+	overlap["expiresAt"] = bson.M{"$not": bson.M{"$lte": time.Now()}}
+	return overlap
+}
+
+// confirmHoldSchedule turns the hold schedule of a booking into a booking schedule that does not expire.
+// The hold must still be unexpired at now, otherwise another booking may already have taken the slot.
+func (s *CarparkService) confirmHoldSchedule(ctx context.Context, carparkId, vehicleId, bookingId int, now time.Time) error {
+	filter := bson.M{
+		"_id": carparkId,
+		"vehicles": bson.M{
+			"$elemMatch": bson.M{
+				"_id": vehicleId,
+				"schedules": bson.M{
+					"$elemMatch": bson.M{
+						"bookingId": bookingId,
+						"type":      models.ScheduleTypeHold,
+						"expiresAt": bson.M{"$gt": now},
+					},
+				},
+			},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"vehicles.$[v].schedules.$[sch].type":   models.ScheduleTypeBooking,
+			"vehicles.$[v].schedules.$[sch].status": models.BookingStatusReserved,
+		},
+		"$unset": bson.M{
+			"vehicles.$[v].schedules.$[sch].expiresAt": "",
+		},
+	}
+	opts := options.UpdateOne().SetArrayFilters([]any{
+		bson.M{"v._id": vehicleId},
+		bson.M{"sch.bookingId": bookingId, "sch.type": models.ScheduleTypeHold},
+	})
+
+	result, err := s.coll.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return fmt.Errorf("failed to confirm hold schedule: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("hold %d: %w", bookingId, apperrors.HoldExpired)
+	}
+
+	return nil
+}
+
+// updateScheduleStatus copies a booking status onto its schedule entry so availability can ignore finished bookings.
+// It does not tell the availability listeners, callers announce terminal statuses once their transaction is committed.
+func (s *CarparkService) updateScheduleStatus(ctx context.Context, carparkId, vehicleId, bookingId int, status string) error {
+	filter := bson.M{"_id": carparkId}
+	update := bson.M{
+		"$set": bson.M{
+			"vehicles.$[v].schedules.$[sch].status": status,
+		},
+	}
+	opts := options.UpdateOne().SetArrayFilters([]any{
+		bson.M{"v._id": vehicleId},
+		bookingScheduleFilter("sch", bookingId),
+	})
+
+	result, err := s.coll.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return fmt.Errorf("failed to update schedule status: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("carpark %d: %w", carparkId, apperrors.CarparkNotFound)
+	}
+
+	return nil
+}
+
+// DeleteScheduleFromVehicle removes a schedule added through POST /schedules, the bookingId is the caller's own id
+// so schedules of records in the bookings collection are never matched
 func (s *CarparkService) DeleteScheduleFromVehicle(req dtos.AddScheduleRequest) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	carpark, err := s.findCarparkById(ctx, req.CarparkId)
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(carpark.Vehicles, func(vehicle models.Vehicle) bool {
+		return vehicle.Id == req.VehicleId
+	})
+	if index < 0 {
+		return fmt.Errorf("vehicle %d in carpark %d: %w", req.VehicleId, req.CarparkId, apperrors.VehicleNotFound)
+	}
+
+	// only legacy schedules without a booking record are removed here, pulling a booking schedule would
+	// free the vehicle without a fee or status history. Bookings are cancelled through their status.
+	legacy := slices.ContainsFunc(carpark.Vehicles[index].Schedules, func(schedule models.Schedule) bool {
+		return schedule.BookingId == req.BookingId && !schedule.IsBooking() && !schedule.IsBlock()
+	})
+	if !legacy {
+		return fmt.Errorf("booking %d on vehicle %d, cancel bookings through POST /bookings/{id}/status: %w",
+			req.BookingId, req.VehicleId, apperrors.ScheduleNotFound)
+	}
+
+	match := bson.M{
+		"bookingId": req.BookingId,
+		"type":      bson.M{"$nin": models.BookingScheduleTypes},
+	}
+	return s.pullSchedules(ctx, req.CarparkId, req.VehicleId, match)
+}
+
+// bookingSchedule matches the schedule of a record in the bookings collection, see models.BookingScheduleTypes
+func bookingSchedule(bookingId int) bson.M {
+	return bson.M{
+		"bookingId": bookingId,
+		"type":      bson.M{"$in": models.BookingScheduleTypes},
+	}
+}
+
+// bookingScheduleFilter is bookingSchedule as an array filter on the identifier
+func bookingScheduleFilter(identifier string, bookingId int) bson.M {
+	return bson.M{
+		identifier + ".bookingId": bookingId,
+		identifier + ".type":      bson.M{"$in": models.BookingScheduleTypes},
+	}
+}
+
+// pullSchedules removes every schedule of the vehicle that matches the query
+func (s *CarparkService) pullSchedules(ctx context.Context, carparkId, vehicleId int, match bson.M) error {
 	// 1. Filter the parent Carpark document
-	filter := bson.M{"_id": req.CarparkId}
+	filter := bson.M{"_id": carparkId}
 
 	// 2. Define the Update logic
-	// $pull removes the element from the schedules array that matches the bookingId
+	// $pull removes the elements from the schedules array that match
 	update := bson.M{
 		"$pull": bson.M{
-			"vehicles.$[v].schedules": bson.M{
-				"bookingId": req.BookingId,
-			},
+			"vehicles.$[v].schedules": match,
 		},
 	}
 
 	// 3. ArrayFilter to identify the specific vehicle inside the array
 	opts := options.UpdateOne().SetArrayFilters([]any{
-		bson.M{"v._id": req.VehicleId},
+		bson.M{"v._id": vehicleId},
 	})
 
 	// 4. Execute the update
@@ -387,8 +1156,11 @@ func (s *CarparkService) DeleteScheduleFromVehicle(req dtos.AddScheduleRequest)
 
 	// 5. Verify if something was actually found
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("carpark %d or vehicle %d not found", req.CarparkId, req.VehicleId)
+		return fmt.Errorf("carpark %d or vehicle %d not found", carparkId, vehicleId)
 	}
 
+	if result.ModifiedCount > 0 {
+		s.availabilityChanged(carparkId)
+	}
 	return nil
 }
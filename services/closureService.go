@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	"example/golang-learn/utilities/db"
+	apperrors "example/golang-learn/utilities/errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ClosureService manages periods where a whole carpark is closed
+type ClosureService struct {
+	coll           *mongo.Collection
+	counters       *mongo.Collection
+	carparkService *CarparkService
+	bookingService *BookingService
+}
+
+func NewClosureService(coll *mongo.Collection, counters *mongo.Collection, carparkService *CarparkService, bookingService *BookingService) *ClosureService {
+	return &ClosureService{
+		coll:           coll,
+		counters:       counters,
+		carparkService: carparkService,
+		bookingService: bookingService,
+	}
+}
+
+// AddClosure closes the carpark for the window. Existing bookings are kept, they are returned so ops can follow up.
+func (s *ClosureService) AddClosure(carparkId int, req dtos.AddClosureRequest) (*dtos.AddClosureResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if !req.End.After(req.Start) {
+		return nil, &apperrors.ValidationError{Fields: map[string][]string{"end": {"End must be after start"}}}
+	}
+
+	// 1. Generate the closure id
+	closureId, err := db.NextSequence(ctx, s.counters, "closures")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate closure id: %w", err)
+	}
+
+	closure := models.Closure{
+		Id:     closureId,
+		Start:  req.Start.UTC(),
+		End:    req.End.UTC(),
+		Reason: req.Reason,
+	}
+
+	// 2. Push it onto the carpark, from here on no new schedule can be added inside it
+	update := bson.M{
+		"$push": bson.M{
+			"closures": closure,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var carpark models.Carpark
+	err = s.coll.FindOneAndUpdate(ctx, bson.M{"_id": carparkId}, update, opts).Decode(&carpark)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("carpark %d: %w", carparkId, apperrors.CarparkNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add closure: %w", err)
+	}
+
+	// 3. Report the bookings that were already made for the window
+	colliding, err := s.bookingService.findOverlapping(ctx, carparkId, closure.Start, closure.End)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.AddClosureResponse{
+		Closure:            closure,
+		CollidingBookings:  colliding,
+		CollidingSchedules: collidingLegacySchedules(&carpark, closure),
+	}, nil
+}
+
+// collidingLegacySchedules finds the schedules inside the closure that have no booking record,
+// blocks are left out since they are the carpark's own
+func collidingLegacySchedules(carpark *models.Carpark, closure models.Closure) []dtos.CollidingSchedule {
+	colliding := []dtos.CollidingSchedule{}
+	for _, vehicle := range carpark.Vehicles {
+		for _, schedule := range vehicle.Schedules {
+			if schedule.IsBooking() || schedule.IsBlock() || !schedule.Blocks() {
+				continue
+			}
+			if !schedule.Overlaps(closure.Start, closure.End) {
+				continue
+			}
+			colliding = append(colliding, dtos.CollidingSchedule{
+				VehicleId: vehicle.Id,
+				BookingId: schedule.BookingId,
+				Start:     schedule.Start,
+				End:       schedule.End,
+			})
+		}
+	}
+	return colliding
+}
+
+func (s *ClosureService) GetClosures(carparkId int) ([]models.Closure, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var carpark models.Carpark
+	err := s.coll.FindOne(ctx, bson.M{"_id": carparkId}).Decode(&carpark)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("carpark %d: %w", carparkId, apperrors.CarparkNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find carpark %d: %w", carparkId, err)
+	}
+
+	return append([]models.Closure{}, carpark.Closures...), nil
+}
+
+func (s *ClosureService) RemoveClosure(carparkId, closureId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": carparkId, "closures._id": closureId}
+	update := bson.M{
+		"$pull": bson.M{
+			"closures": bson.M{"_id": closureId},
+		},
+	}
+
+	result, err := s.coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to remove closure: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("closure %d in carpark %d: %w", closureId, carparkId, apperrors.ClosureNotFound)
+	}
+
+	// the vehicles are free again for the closed window
+	s.carparkService.availabilityChanged(carparkId)
+	return nil
+}
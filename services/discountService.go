@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	"example/golang-learn/utilities/db"
+	apperrors "example/golang-learn/utilities/errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// DiscountService manages the time-bounded percentage discounts of individual vehicles
+type DiscountService struct {
+	coll           *mongo.Collection
+	counters       *mongo.Collection
+	carparkService *CarparkService
+}
+
+func NewDiscountService(coll *mongo.Collection, counters *mongo.Collection, carparkService *CarparkService) *DiscountService {
+	return &DiscountService{
+		coll:           coll,
+		counters:       counters,
+		carparkService: carparkService,
+	}
+}
+
+func (s *DiscountService) AddDiscount(vehicleId int, req dtos.AddDiscountRequest) (*models.Discount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 1. Validate
+	fields := make(map[string][]string)
+	if req.Percent <= 0 || req.Percent > 100 {
+		fields["percent"] = append(fields["percent"], "Percent must be above 0 and at most 100")
+	}
+	if !req.End.After(req.Start) {
+		fields["end"] = append(fields["end"], "End must be after start")
+	}
+	if len(fields) > 0 {
+		return nil, &apperrors.ValidationError{Fields: fields}
+	}
+
+	carpark, _, err := s.carparkService.findVehicle(ctx, vehicleId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Generate the discount id
+	discountId, err := db.NextSequence(ctx, s.counters, "discounts")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate discount id: %w", err)
+	}
+
+	discount := models.Discount{
+		Id:      discountId,
+		Percent: req.Percent,
+		Start:   req.Start.UTC(),
+		End:     req.End.UTC(),
+	}
+
+	// 3. Push it only if no other discount of the vehicle overlaps, so a window never has two prices
+	filter := bson.M{
+		"_id": carpark.Id,
+		"vehicles": bson.M{
+			"$elemMatch": bson.M{
+				"_id":       vehicleId,
+				"discounts": bson.M{"$not": bson.M{"$elemMatch": windowOverlap(discount.Start, discount.End)}},
+			},
+		},
+	}
+	update := bson.M{
+		"$push": bson.M{
+			"vehicles.$[v].discounts": discount,
+		},
+	}
+	opts := options.UpdateOne().SetArrayFilters([]any{
+		bson.M{"v._id": vehicleId},
+	})
+
+	result, err := s.coll.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add discount: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		// the vehicle may have been removed since it was looked up
+		if _, _, err := s.carparkService.findVehicle(ctx, vehicleId); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("vehicle %d: %w", vehicleId, apperrors.DiscountOverlap)
+	}
+
+	return &discount, nil
+}
+
+func (s *DiscountService) GetDiscounts(vehicleId int) ([]models.Discount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, vehicle, err := s.carparkService.findVehicle(ctx, vehicleId)
+	if err != nil {
+		return nil, err
+	}
+
+	discounts := slices.Clone(vehicle.Discounts)
+	if discounts == nil {
+		discounts = []models.Discount{}
+	}
+	return discounts, nil
+}
+
+func (s *DiscountService) RemoveDiscount(vehicleId, discountId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	carpark, vehicle, err := s.carparkService.findVehicle(ctx, vehicleId)
+	if err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(vehicle.Discounts, func(discount models.Discount) bool {
+		return discount.Id == discountId
+	}) {
+		return fmt.Errorf("discount %d on vehicle %d: %w", discountId, vehicleId, apperrors.DiscountNotFound)
+	}
+
+	update := bson.M{
+		"$pull": bson.M{
+			"vehicles.$[v].discounts": bson.M{"_id": discountId},
+		},
+	}
+	opts := options.UpdateOne().SetArrayFilters([]any{
+		bson.M{"v._id": vehicleId},
+	})
+
+	if _, err = s.coll.UpdateOne(ctx, bson.M{"_id": carpark.Id}, update, opts); err != nil {
+		return fmt.Errorf("failed to remove discount: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	"example/golang-learn/utilities/db"
+	apperrors "example/golang-learn/utilities/errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// CreateGroupBooking books several vehicles, possibly in different carparks, for the same window.
+// The schedules and bookings are written in one transaction so either the whole group is booked or nothing is.
+func (s *BookingService) CreateGroupBooking(req dtos.CreateGroupBookingRequest) (*dtos.GroupBookingResponse, error) {
+	// 1. Validate
+	if len(req.Vehicles) == 0 {
+		return nil, &apperrors.ValidationError{Fields: map[string][]string{"vehicles": {"At least one vehicle is required"}}}
+	}
+	seen := make(map[dtos.GroupBookingVehicle]bool)
+	for _, vehicle := range req.Vehicles {
+		if seen[vehicle] {
+			return nil, &apperrors.ValidationError{Fields: map[string][]string{"vehicles": {fmt.Sprintf("Vehicle %d is listed twice", vehicle.VehicleId)}}}
+		}
+		seen[vehicle] = true
+	}
+	if err := validateBookingWindow(s.settingService, req.Start, req.End); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	checked := make(map[int]bool)
+	for _, vehicle := range req.Vehicles {
+		if checked[vehicle.CarparkId] {
+			continue
+		}
+		if err := s.carparkService.checkOpeningHours(ctx, vehicle.CarparkId, req.Start, req.End); err != nil {
+			return nil, err
+		}
+		checked[vehicle.CarparkId] = true
+	}
+
+	// 2. Reserve the ids, outside the transaction so a retried transaction does not burn new ones
+	firstBookingId, err := db.NextSequenceRange(ctx, s.counters, "bookings", len(req.Vehicles))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate booking ids: %w", err)
+	}
+	groupId, err := db.NextSequence(ctx, s.counters, "groups")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate group id: %w", err)
+	}
+
+	now := time.Now().UTC()
+	window := []dtos.TimeWindow{{Start: req.Start.UTC(), End: req.End.UTC()}}
+	bookings := make([]models.Booking, 0, len(req.Vehicles))
+	for i, vehicle := range req.Vehicles {
+		prices, err := s.agreedPrices(ctx, vehicle.VehicleId, window)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, models.Booking{
+			Id:        firstBookingId + i,
+			UserId:    req.UserId,
+			CarparkId: vehicle.CarparkId,
+			VehicleId: vehicle.VehicleId,
+			GroupId:   groupId,
+			Start:     req.Start.UTC(),
+			End:       req.End.UTC(),
+			Status:    models.BookingStatusReserved,
+			StatusHistory: []models.BookingStatusChange{
+				{Status: models.BookingStatusReserved, At: now},
+			},
+			Price:     &prices[0],
+			CreatedAt: now,
+		})
+	}
+
+	// 3. Claim every slot and record the bookings in one transaction, any conflict aborts all of it
+	session, err := s.client.StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		for _, booking := range bookings {
+			schedule := models.Schedule{
+				Type:      models.ScheduleTypeBooking,
+				BookingId: booking.Id,
+				Status:    booking.Status,
+				Start:     booking.Start,
+				End:       booking.End,
+			}
+			if err := s.carparkService.addSchedule(ctx, booking.CarparkId, booking.VehicleId, schedule); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := s.coll.InsertMany(ctx, bookings); err != nil {
+			return nil, fmt.Errorf("failed to save bookings: %w", err)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.GroupBookingResponse{GroupId: groupId, Bookings: bookings}, nil
+}
+
+// CancelGroup cancels every reserved booking of the group in one transaction
+func (s *BookingService) CancelGroup(groupId int) ([]models.Booking, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.coll.Find(ctx, bson.M{"groupId": groupId})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find group %d: %w", groupId, err)
+	}
+	defer cursor.Close(ctx)
+
+	var group []models.Booking
+	if err := cursor.All(ctx, &group); err != nil {
+		return nil, fmt.Errorf("decoding failed: %w", err)
+	}
+	if len(group) == 0 {
+		return nil, fmt.Errorf("group %d: %w", groupId, apperrors.BookingNotFound)
+	}
+
+	return s.cancelReserved(ctx, group)
+}
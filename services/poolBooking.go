@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	apperrors "example/golang-learn/utilities/errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// fragmentationHorizon caps how far either side of a booking a gap is measured, an idle vehicle scores the cap
+const fragmentationHorizon = 24 * time.Hour
+
+// CreatePoolBooking books whichever matching vehicle fits the window most tightly.
+// Candidates are tried in order, so a vehicle taken by a concurrent request just moves on to the next one.
+func (s *BookingService) CreatePoolBooking(req dtos.CreatePoolBookingRequest) (*models.Booking, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := validateBookingWindow(s.settingService, req.Start, req.End); err != nil {
+		return nil, err
+	}
+	if err := s.carparkService.checkOpeningHours(ctx, req.CarparkId, req.Start, req.End); err != nil {
+		return nil, err
+	}
+
+	// 1. Rank the vehicles that are free for the window
+	candidates, err := s.carparkService.poolCandidates(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. A promo code limited to some price groups narrows the candidates instead of failing on the first one
+	if req.PromoCode != "" && len(candidates) > 0 {
+		promo, err := s.promoService.findPromoCode(ctx, req.PromoCode)
+		if err != nil && !errors.Is(err, apperrors.PromoCodeNotFound) {
+			return nil, err
+		}
+		// an unknown code is left to CreateBooking, which rejects it with the usual message
+		if promo != nil && len(promo.PriceGroupIds) > 0 {
+			candidates = slices.DeleteFunc(candidates, func(c poolCandidate) bool {
+				return !slices.Contains(promo.PriceGroupIds, c.priceGroupId)
+			})
+			if len(candidates) == 0 {
+				return nil, promoCodeError("Promo code does not apply to any free vehicle")
+			}
+		}
+	}
+
+	// 3. Claim the first one that is still free, addSchedule rejects the rest atomically
+	for _, candidate := range candidates {
+		booking, err := s.CreateBooking(dtos.CreateBookingRequest{
+			UserId:    req.UserId,
+			CarparkId: req.CarparkId,
+			VehicleId: candidate.vehicleId,
+			Start:     req.Start,
+			End:       req.End,
+			PromoCode: req.PromoCode,
+		})
+		var conflict *apperrors.ScheduleConflictError
+		if errors.As(err, &conflict) {
+			continue
+		}
+		return booking, err
+	}
+
+	return nil, fmt.Errorf("carpark %d: %w", req.CarparkId, apperrors.NoVehicleAvailable)
+}
+
+type poolCandidate struct {
+	vehicleId    int
+	priceGroupId int
+	score        time.Duration
+}
+
+// poolCandidates returns the matching free vehicles, least fragmenting first
+func (s *CarparkService) poolCandidates(ctx context.Context, req dtos.CreatePoolBookingRequest) ([]poolCandidate, error) {
+	carpark, err := s.findCarparkById(ctx, req.CarparkId)
+	if err != nil {
+		return nil, err
+	}
+
+	buffers, err := s.loadBufferRules()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []poolCandidate
+	for i := range carpark.Vehicles {
+		vehicle := &carpark.Vehicles[i]
+		buffer := buffers.forVehicle(carpark, vehicle)
+		if !vehicleMatches(vehicle, req.PriceGroupIds, req.VehicleTypeIds, req.NumSeats) ||
+			!vehicleIsFree(carpark, vehicle, buffer, req.Start, req.End) {
+			continue
+		}
+		candidates = append(candidates, poolCandidate{
+			vehicleId:    vehicle.Id,
+			priceGroupId: vehicle.PriceGroupId,
+			score:        fragmentation(vehicle.Schedules, carpark.Closures, buffer, req.Start, req.End),
+		})
+	}
+
+	// Ties keep the carpark's vehicle order so the choice is stable
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score < candidates[j].score
+	})
+	return candidates, nil
+}
+
+// fragmentation is the idle time the booking would leave next to the vehicle's neighbouring schedules,
+// a vehicle whose gap the window fills exactly scores 0
+func fragmentation(schedules []models.Schedule, closures []models.Closure, buffer time.Duration, start, end time.Time) time.Duration {
+	from := start.Add(-fragmentationHorizon)
+	to := end.Add(fragmentationHorizon)
+
+	// 1. Nearest busy window ending before the booking and starting after it
+	before, after := from, to
+	for _, window := range busyWindows(schedules, closures, from, to, buffer) {
+		if !window.End.After(start) && window.End.After(before) {
+			before = window.End
+		}
+		if !window.Start.Before(end) && window.Start.Before(after) {
+			after = window.Start
+		}
+	}
+
+	return start.Sub(before) + after.Sub(end)
+}
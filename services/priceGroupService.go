@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	"example/golang-learn/utilities/db"
+	apperrors "example/golang-learn/utilities/errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// PriceGroupService manages the price group catalog. Vehicles keep a copy of the group name,
+// which is rewritten here whenever a group is renamed or vehicles move between groups.
+type PriceGroupService struct {
+	client   *mongo.Client
+	coll     *mongo.Collection
+	counters *mongo.Collection
+	carparks *mongo.Collection
+}
+
+func NewPriceGroupService(client *mongo.Client, coll *mongo.Collection, counters *mongo.Collection, carparks *mongo.Collection) *PriceGroupService {
+	return &PriceGroupService{
+		client:   client,
+		coll:     coll,
+		counters: counters,
+		carparks: carparks,
+	}
+}
+
+func (s *PriceGroupService) CreatePriceGroup(req dtos.PriceGroupRequest) (*models.PriceGroup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := validatePriceGroup(req); err != nil {
+		return nil, err
+	}
+
+	priceGroupId, err := db.NextSequence(ctx, s.counters, "priceGroups")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate price group id: %w", err)
+	}
+
+	priceGroup := models.PriceGroup{
+		Id:                priceGroupId,
+		Name:              req.Name,
+		HourlyRate:        req.HourlyRate,
+		WeekendHourlyRate: req.WeekendHourlyRate,
+		DailyCap:          req.DailyCap,
+		PerKmRate:         req.PerKmRate,
+		PeakBands:         req.PeakBands,
+		CreatedAt:         time.Now().UTC(),
+	}
+
+	if _, err = s.coll.InsertOne(ctx, priceGroup); err != nil {
+		return nil, fmt.Errorf("failed to save price group: %w", err)
+	}
+
+	return &priceGroup, nil
+}
+
+func (s *PriceGroupService) GetPriceGroups() ([]models.PriceGroup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	cursor, err := s.coll.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find price groups: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	priceGroups := []models.PriceGroup{}
+	if err := cursor.All(ctx, &priceGroups); err != nil {
+		return nil, fmt.Errorf("decoding failed: %w", err)
+	}
+
+	return priceGroups, nil
+}
+
+func (s *PriceGroupService) GetPriceGroup(priceGroupId int) (*models.PriceGroup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return findPriceGroup(ctx, s.coll, priceGroupId)
+}
+
+// findPriceGroup looks a group up in the price_groups collection, shared by every service that needs a tariff
+func findPriceGroup(ctx context.Context, priceGroups *mongo.Collection, priceGroupId int) (*models.PriceGroup, error) {
+	var priceGroup models.PriceGroup
+	err := priceGroups.FindOne(ctx, bson.M{"_id": priceGroupId}).Decode(&priceGroup)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("price group %d: %w", priceGroupId, apperrors.PriceGroupNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find price group %d: %w", priceGroupId, err)
+	}
+	return &priceGroup, nil
+}
+
+// UpdatePriceGroup replaces the tariff, a new name is copied onto every vehicle of the group in the same transaction
+func (s *PriceGroupService) UpdatePriceGroup(priceGroupId int, req dtos.PriceGroupRequest) (*models.PriceGroup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := validatePriceGroup(req); err != nil {
+		return nil, err
+	}
+
+	// 1. Update the catalog
+	filter := bson.M{"_id": priceGroupId}
+	update := bson.M{
+		"$set": bson.M{
+			"name":              req.Name,
+			"hourlyRate":        req.HourlyRate,
+			"weekendHourlyRate": req.WeekendHourlyRate,
+			"dailyCap":          req.DailyCap,
+			"perKmRate":         req.PerKmRate,
+			"peakBands":         req.PeakBands,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	session, err := s.client.StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	var priceGroup models.PriceGroup
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		err := s.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&priceGroup)
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("price group %d: %w", priceGroupId, apperrors.PriceGroupNotFound)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update price group %d: %w", priceGroupId, err)
+		}
+
+		// 2. Keep the name on the vehicles in sync
+		return nil, s.setVehicleGroup(ctx, bson.M{"v.priceGroupId": priceGroupId}, &priceGroup)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &priceGroup, nil
+}
+
+// DeletePriceGroup only removes groups no vehicle uses any more, reassign the vehicles first
+func (s *PriceGroupService) DeletePriceGroup(priceGroupId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, err := s.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	// count and delete in one transaction so a vehicle added to the group in between is not left without one
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		inUse, err := s.carparks.CountDocuments(ctx, bson.M{"vehicles.priceGroupId": priceGroupId})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count vehicles of price group %d: %w", priceGroupId, err)
+		}
+		if inUse > 0 {
+			return nil, fmt.Errorf("price group %d: %w", priceGroupId, apperrors.PriceGroupInUse)
+		}
+
+		result, err := s.coll.DeleteOne(ctx, bson.M{"_id": priceGroupId})
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete price group %d: %w", priceGroupId, err)
+		}
+		if result.DeletedCount == 0 {
+			return nil, fmt.Errorf("price group %d: %w", priceGroupId, apperrors.PriceGroupNotFound)
+		}
+		return nil, nil
+	})
+	return err
+}
+
+// ReassignVehicles moves vehicles to another price group across every carpark
+func (s *PriceGroupService) ReassignVehicles(req dtos.ReassignPriceGroupRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 1. Validate
+	if req.FromPriceGroupId == 0 && len(req.VehicleIds) == 0 {
+		return &apperrors.ValidationError{Fields: map[string][]string{"vehicleIds": {"VehicleIds or fromPriceGroupId is required"}}}
+	}
+	priceGroup, err := findPriceGroup(ctx, s.coll, req.ToPriceGroupId)
+	if err != nil {
+		return err
+	}
+
+	// 2. Select the vehicles to move
+	match := bson.M{}
+	if req.FromPriceGroupId != 0 {
+		match["v.priceGroupId"] = req.FromPriceGroupId
+	}
+	if len(req.VehicleIds) > 0 {
+		match["v._id"] = bson.M{"$in": req.VehicleIds}
+	}
+
+	return s.setVehicleGroup(ctx, match, priceGroup)
+}
+
+// setVehicleGroup sets the price group of every vehicle matching the "v" array filter, in every carpark
+func (s *PriceGroupService) setVehicleGroup(ctx context.Context, vehicleMatch bson.M, priceGroup *models.PriceGroup) error {
+	update := bson.M{
+		"$set": bson.M{
+			"vehicles.$[v].priceGroupId":   priceGroup.Id,
+			"vehicles.$[v].priceGroupName": priceGroup.Name,
+		},
+	}
+	opts := options.UpdateMany().SetArrayFilters([]any{vehicleMatch})
+
+	if _, err := s.carparks.UpdateMany(ctx, bson.M{}, update, opts); err != nil {
+		return fmt.Errorf("failed to update vehicles of price group %d: %w", priceGroup.Id, err)
+	}
+	return nil
+}
+
+func validatePriceGroup(req dtos.PriceGroupRequest) error {
+	fields := make(map[string][]string)
+	if req.Name == "" {
+		fields["name"] = append(fields["name"], "Name is required")
+	}
+	if req.HourlyRate < 0 || req.WeekendHourlyRate < 0 || req.DailyCap < 0 || req.PerKmRate < 0 {
+		fields["rates"] = append(fields["rates"], "Rates cannot be negative")
+	}
+	for i, band := range req.PeakBands {
+		key := fmt.Sprintf("peakBands[%d]", i)
+		if !validClock(band.Start) || !validClock(band.End) || band.Start >= band.End {
+			fields[key] = append(fields[key], "Start and end must be HH:MM with start before end")
+		}
+		if band.HourlyRate < 0 {
+			fields[key] = append(fields[key], "Rate cannot be negative")
+		}
+	}
+	if len(fields) > 0 {
+		return &apperrors.ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// validClock accepts "HH:MM" from "00:00" to "24:00"
+func validClock(clock string) bool {
+	if clock == "24:00" {
+		return true
+	}
+	_, err := time.Parse("15:04", clock)
+	return err == nil && len(clock) == 5
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"example/golang-learn/models"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type charges struct {
+	time     float64
+	distance float64
+}
+
+func (c charges) total() float64 {
+	return roundCents(c.time + c.distance)
+}
+
+// discounted takes the discount's percentage off the time charge, distance is never discounted
+func (c charges) discounted(discount *models.Discount) charges {
+	if discount == nil || discount.Percent <= 0 {
+		return c
+	}
+	c.time = roundCents(c.time * (100 - min(discount.Percent, 100)) / 100)
+	return c
+}
+
+// priceWindow prices [start, end) with the group's tariff in Asia/Singapore time.
+// Each local day is split at the peak band edges, every piece is charged at the rate in force,
+// and the day's sum is capped at DailyCap.
+func priceWindow(group *models.PriceGroup, start, end time.Time, estimatedKm float64) charges {
+	var timeCharge float64
+
+	// 1. One local day at a time so the daily cap applies per calendar day
+	local := start.In(models.OpeningHoursLocation)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, models.OpeningHoursLocation)
+	for day.Before(end) {
+		nextDay := day.AddDate(0, 0, 1)
+
+		// 2. Split the day at every band edge, then charge the part of each piece inside the window
+		var dayCharge float64
+		edges := bandEdges(group, day)
+		for i := 0; i < len(edges)-1; i++ {
+			from := maxTime(edges[i], start)
+			to := minTime(edges[i+1], end)
+			if !from.Before(to) {
+				continue
+			}
+			dayCharge += to.Sub(from).Hours() * hourlyRateAt(group, edges[i])
+		}
+
+		if group.DailyCap > 0 {
+			dayCharge = min(dayCharge, group.DailyCap)
+		}
+		timeCharge += dayCharge
+		day = nextDay
+	}
+
+	return charges{
+		time:     roundCents(timeCharge),
+		distance: roundCents(estimatedKm * group.PerKmRate),
+	}
+}
+
+// bandEdges returns midnight, the start and end of every band applying that day, and the next midnight, sorted
+func bandEdges(group *models.PriceGroup, day time.Time) []time.Time {
+	edges := []time.Time{day, day.AddDate(0, 0, 1)}
+	for _, band := range group.PeakBands {
+		if !bandAppliesOn(band, day.Weekday()) {
+			continue
+		}
+		edges = append(edges, day.Add(clockOffset(band.Start)), day.Add(clockOffset(band.End)))
+	}
+
+	slices.SortFunc(edges, func(a, b time.Time) int { return a.Compare(b) })
+	return slices.CompactFunc(edges, func(a, b time.Time) bool { return a.Equal(b) })
+}
+
+// hourlyRateAt is the first peak band covering t, otherwise the weekend or the normal rate
+func hourlyRateAt(group *models.PriceGroup, t time.Time) float64 {
+	local := t.In(models.OpeningHoursLocation)
+	clock := local.Format("15:04")
+
+	for _, band := range group.PeakBands {
+		if bandAppliesOn(band, local.Weekday()) && band.Start <= clock && clock < band.End {
+			return band.HourlyRate
+		}
+	}
+
+	weekend := local.Weekday() == time.Saturday || local.Weekday() == time.Sunday
+	if weekend && group.WeekendHourlyRate > 0 {
+		return group.WeekendHourlyRate
+	}
+	return group.HourlyRate
+}
+
+func bandAppliesOn(band models.PeakBand, weekday time.Weekday) bool {
+	return len(band.Weekdays) == 0 || slices.Contains(band.Weekdays, weekday)
+}
+
+// clockOffset turns "HH:MM" into the time since midnight, "24:00" is a full day
+func clockOffset(clock string) time.Duration {
+	hours, minutes, _ := strings.Cut(clock, ":")
+	h, _ := strconv.Atoi(hours)
+	m, _ := strconv.Atoi(minutes)
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
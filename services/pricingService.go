@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	apperrors "example/golang-learn/utilities/errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// PricingService prices vehicles with the tariff of their price group
+type PricingService struct {
+	priceGroups    *mongo.Collection
+	carparkService *CarparkService
+	promoService   *PromoService
+}
+
+func NewPricingService(priceGroups *mongo.Collection, carparkService *CarparkService, promoService *PromoService) *PricingService {
+	return &PricingService{
+		priceGroups:    priceGroups,
+		carparkService: carparkService,
+		promoService:   promoService,
+	}
+}
+
+func (s *PricingService) Quote(req dtos.QuoteRequest) (*dtos.Quote, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	quote, _, err := s.quote(ctx, req)
+	return quote, err
+}
+
+// quote prices the vehicle for the window and applies the promo code of the request,
+// the code is returned too so a booking can redeem it
+func (s *PricingService) quote(ctx context.Context, req dtos.QuoteRequest) (*dtos.Quote, *models.PromoCode, error) {
+	// 1. Validate
+	fields := make(map[string][]string)
+	if !req.End.After(req.Start) {
+		fields["end"] = append(fields["end"], "End must be after start")
+	}
+	if req.EstimatedKm < 0 {
+		fields["estimatedKm"] = append(fields["estimatedKm"], "EstimatedKm cannot be negative")
+	}
+	if len(fields) > 0 {
+		return nil, nil, &apperrors.ValidationError{Fields: fields}
+	}
+
+	// 2. Find the vehicle and its tariff
+	carpark, vehicle, err := s.carparkService.findVehicle(ctx, req.VehicleId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	group, err := findPriceGroup(ctx, s.priceGroups, vehicle.PriceGroupId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 3. Price the window, with the vehicle's discount when one covers it
+	discount := vehicle.ActiveDiscount(req.Start, req.End)
+	full := priceWindow(group, req.Start, req.End, req.EstimatedKm)
+	priced := full.discounted(discount)
+	quote := &dtos.Quote{
+		VehicleId:      vehicle.Id,
+		PriceGroupId:   group.Id,
+		PriceGroupName: group.Name,
+		Start:          req.Start,
+		End:            req.End,
+		TimeCharge:     priced.time,
+		DistanceCharge: priced.distance,
+		Total:          priced.total(),
+	}
+	if discount != nil {
+		quote.DiscountId = discount.Id
+		quote.OriginalTotal = full.total()
+	}
+
+	// 4. The promo code comes off the total, after the vehicle discount
+	if req.PromoCode == "" {
+		return quote, nil, nil
+	}
+	promo, err := s.promoService.applicable(ctx, req.PromoCode, req.UserId, carpark.Id, vehicle.PriceGroupId, req.Start, req.End)
+	if err != nil {
+		return nil, nil, err
+	}
+	quote.PromoCode = promo.Code
+	quote.PromoDiscount = roundCents(promo.DiscountOn(quote.Total))
+	quote.Total = roundCents(quote.Total - quote.PromoDiscount)
+	return quote, promo, nil
+}
+
+// windowPrices prices the vehicle for each window with its tariff and the discount covering that window
+func (s *PricingService) windowPrices(ctx context.Context, vehicleId int, windows []dtos.TimeWindow) ([]float64, error) {
+	_, vehicle, err := s.carparkService.findVehicle(ctx, vehicleId)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := findPriceGroup(ctx, s.priceGroups, vehicle.PriceGroupId)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make([]float64, len(windows))
+	for i, window := range windows {
+		discount := vehicle.ActiveDiscount(window.Start, window.End)
+		prices[i] = priceWindow(group, window.Start, window.End, 0).discounted(discount).total()
+	}
+	return prices, nil
+}
+
+// PriceCarparks fills in the quoted total of every vehicle in the search results.
+// Vehicles whose price group is not in the catalog are left without a quote.
+func (s *PricingService) PriceCarparks(carparks []dtos.CarparkResult, start, end time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 1. Load every price group used by the results in one query
+	var groupIds []int
+	for _, carpark := range carparks {
+		for _, vehicle := range carpark.Vehicles {
+			groupIds = append(groupIds, vehicle.PriceGroupId)
+		}
+	}
+	if len(groupIds) == 0 {
+		return nil
+	}
+
+	cursor, err := s.priceGroups.Find(ctx, bson.M{"_id": bson.M{"$in": groupIds}})
+	if err != nil {
+		return fmt.Errorf("failed to find price groups: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var groups []models.PriceGroup
+	if err := cursor.All(ctx, &groups); err != nil {
+		return fmt.Errorf("decoding failed: %w", err)
+	}
+
+	// 2. Every vehicle of a group costs the same for the window before discounts, so price each group once
+	prices := make(map[int]charges, len(groups))
+	for i := range groups {
+		prices[groups[i].Id] = priceWindow(&groups[i], start, end, 0)
+	}
+
+	for i := range carparks {
+		for j := range carparks[i].Vehicles {
+			vehicle := &carparks[i].Vehicles[j]
+			if priced, ok := prices[vehicle.PriceGroupId]; ok {
+				total := priced.discounted(vehicle.Slashed).total()
+				vehicle.QuotedTotal = &total
+				if vehicle.Slashed != nil {
+					original := priced.total()
+					vehicle.OriginalTotal = &original
+				}
+			}
+		}
+	}
+	return nil
+}
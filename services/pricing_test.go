@@ -0,0 +1,143 @@
+package services
+
+import (
+	"example/golang-learn/models"
+	"testing"
+	"time"
+)
+
+func testPriceGroup() *models.PriceGroup {
+	return &models.PriceGroup{
+		Id:                1,
+		HourlyRate:        10,
+		WeekendHourlyRate: 12,
+		DailyCap:          100,
+		PerKmRate:         0.5,
+		PeakBands: []models.PeakBand{
+			{
+				Weekdays:   []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+				Start:      "17:00",
+				End:        "20:00",
+				HourlyRate: 20,
+			},
+		},
+	}
+}
+
+func TestPriceWindow(t *testing.T) {
+	// 2026-10-19 is a Monday, 2026-10-24 a Saturday
+	tests := []struct {
+		name         string
+		start        string
+		end          string
+		km           float64
+		wantTime     float64
+		wantDistance float64
+	}{
+		{"off peak", "2026-10-19 09:00", "2026-10-19 11:00", 0, 20, 0},
+		{"part of an hour", "2026-10-19 09:00", "2026-10-19 09:20", 0, 3.33, 0},
+		{"runs into the peak band", "2026-10-19 16:00", "2026-10-19 18:00", 0, 30, 0},
+		{"starts inside the peak band", "2026-10-19 18:00", "2026-10-19 19:30", 0, 30, 0},
+		{"runs out of the peak band", "2026-10-19 19:00", "2026-10-19 21:00", 0, 30, 0},
+		{"weekday band does not apply on the weekend", "2026-10-24 17:00", "2026-10-24 18:00", 0, 12, 0},
+		{"crosses midnight into the weekend", "2026-10-23 23:00", "2026-10-24 01:00", 0, 22, 0},
+		{"whole day is capped", "2026-10-19 00:00", "2026-10-20 00:00", 0, 100, 0},
+		{"cap applies per calendar day", "2026-10-19 20:00", "2026-10-20 20:00", 0, 140, 0},
+		{"distance is charged per km", "2026-10-19 09:00", "2026-10-19 10:00", 10, 10, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := priceWindow(testPriceGroup(), sgt(t, tt.start).UTC(), sgt(t, tt.end).UTC(), tt.km)
+			if got.time != tt.wantTime {
+				t.Errorf("time charge %v, want %v", got.time, tt.wantTime)
+			}
+			if got.distance != tt.wantDistance {
+				t.Errorf("distance charge %v, want %v", got.distance, tt.wantDistance)
+			}
+		})
+	}
+}
+
+func TestPriceWindowUncapped(t *testing.T) {
+	group := testPriceGroup()
+	group.DailyCap = 0
+
+	// 21 hours at 10 and the 3 peak hours at 20
+	got := priceWindow(group, sgt(t, "2026-10-19 00:00"), sgt(t, "2026-10-20 00:00"), 0)
+	if got.time != 270 {
+		t.Errorf("time charge %v, want 270", got.time)
+	}
+}
+
+func TestBandEdges(t *testing.T) {
+	tests := []struct {
+		name  string
+		bands []models.PeakBand
+		day   string
+		want  []string
+	}{
+		{
+			name:  "weekday band",
+			bands: testPriceGroup().PeakBands,
+			day:   "2026-10-19 00:00",
+			want:  []string{"2026-10-19 00:00", "2026-10-19 17:00", "2026-10-19 20:00", "2026-10-20 00:00"},
+		},
+		{
+			name:  "band not applying that day",
+			bands: testPriceGroup().PeakBands,
+			day:   "2026-10-24 00:00",
+			want:  []string{"2026-10-24 00:00", "2026-10-25 00:00"},
+		},
+		{
+			name: "bands touching midnight are not doubled",
+			bands: []models.PeakBand{
+				{Start: "00:00", End: "06:00", HourlyRate: 5},
+				{Start: "22:00", End: "24:00", HourlyRate: 5},
+			},
+			day:  "2026-10-19 00:00",
+			want: []string{"2026-10-19 00:00", "2026-10-19 06:00", "2026-10-19 22:00", "2026-10-20 00:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &models.PriceGroup{HourlyRate: 10, PeakBands: tt.bands}
+			edges := bandEdges(group, sgt(t, tt.day))
+
+			if len(edges) != len(tt.want) {
+				t.Fatalf("got %d edges %v, want %v", len(edges), edges, tt.want)
+			}
+			for i := range edges {
+				if !edges[i].Equal(sgt(t, tt.want[i])) {
+					t.Errorf("edge %d is %s, want %s", i, edges[i].In(models.OpeningHoursLocation), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestChargesDiscounted(t *testing.T) {
+	tests := []struct {
+		name     string
+		discount *models.Discount
+		wantTime float64
+	}{
+		{"no discount", nil, 40},
+		{"percentage off the time charge", &models.Discount{Percent: 30}, 28},
+		{"rounded to cents", &models.Discount{Percent: 33.3}, 26.68},
+		{"free", &models.Discount{Percent: 100}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := charges{time: 40, distance: 5}.discounted(tt.discount)
+			if got.time != tt.wantTime {
+				t.Errorf("time charge %v, want %v", got.time, tt.wantTime)
+			}
+			if got.distance != 5 {
+				t.Errorf("distance charge %v, want it untouched at 5", got.distance)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	apperrors "example/golang-learn/utilities/errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// PromoService manages promo codes and counts their redemptions
+type PromoService struct {
+	coll        *mongo.Collection
+	redemptions *mongo.Collection
+}
+
+func NewPromoService(coll *mongo.Collection, redemptions *mongo.Collection) *PromoService {
+	return &PromoService{
+		coll:        coll,
+		redemptions: redemptions,
+	}
+}
+
+func (s *PromoService) CreatePromoCode(req dtos.PromoCodeRequest) (*models.PromoCode, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 1. Validate
+	fields := make(map[string][]string)
+	code := normalizePromoCode(req.Code)
+	if code == "" {
+		fields["code"] = append(fields["code"], "Code is required")
+	}
+	switch req.Type {
+	case models.PromoTypePercentage:
+		if req.Value <= 0 || req.Value > 100 {
+			fields["value"] = append(fields["value"], "Percentage must be between 0 and 100")
+		}
+	case models.PromoTypeFixed:
+		if req.Value <= 0 {
+			fields["value"] = append(fields["value"], "Value must be positive")
+		}
+	default:
+		fields["type"] = append(fields["type"], "Type must be percentage or fixed")
+	}
+	if !req.ValidTo.After(req.ValidFrom) {
+		fields["validTo"] = append(fields["validTo"], "ValidTo must be after validFrom")
+	}
+	if req.MaxRedemptions < 0 || req.MaxPerUser < 0 || req.MinBookingMinutes < 0 {
+		fields["limits"] = append(fields["limits"], "Limits cannot be negative")
+	}
+	if len(fields) > 0 {
+		return nil, &apperrors.ValidationError{Fields: fields}
+	}
+
+	promo := models.PromoCode{
+		Code:              code,
+		Type:              req.Type,
+		Value:             req.Value,
+		ValidFrom:         req.ValidFrom.UTC(),
+		ValidTo:           req.ValidTo.UTC(),
+		MaxRedemptions:    req.MaxRedemptions,
+		MaxPerUser:        req.MaxPerUser,
+		MinBookingMinutes: req.MinBookingMinutes,
+		PriceGroupIds:     req.PriceGroupIds,
+		CarparkIds:        req.CarparkIds,
+		CreatedAt:         time.Now().UTC(),
+	}
+
+	// 2. The code is the id, so a second code with the same name is rejected by the index
+	if _, err := s.coll.InsertOne(ctx, promo); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, &apperrors.ValidationError{Fields: map[string][]string{"code": {"Code already exists"}}}
+		}
+		return nil, fmt.Errorf("failed to save promo code: %w", err)
+	}
+
+	return &promo, nil
+}
+
+func (s *PromoService) GetPromoCodes() ([]models.PromoCode, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"createdAt": -1})
+	cursor, err := s.coll.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find promo codes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	promos := []models.PromoCode{}
+	if err := cursor.All(ctx, &promos); err != nil {
+		return nil, fmt.Errorf("decoding failed: %w", err)
+	}
+
+	return promos, nil
+}
+
+func (s *PromoService) GetPromoCode(code string) (*models.PromoCode, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return s.findPromoCode(ctx, code)
+}
+
+func (s *PromoService) DeletePromoCode(code string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	code = normalizePromoCode(code)
+	result, err := s.coll.DeleteOne(ctx, bson.M{"_id": code})
+	if err != nil {
+		return fmt.Errorf("failed to delete promo code %s: %w", code, err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("promo code %s: %w", code, apperrors.PromoCodeNotFound)
+	}
+
+	return nil
+}
+
+func (s *PromoService) findPromoCode(ctx context.Context, code string) (*models.PromoCode, error) {
+	code = normalizePromoCode(code)
+
+	var promo models.PromoCode
+	err := s.coll.FindOne(ctx, bson.M{"_id": code}).Decode(&promo)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("promo code %s: %w", code, apperrors.PromoCodeNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find promo code %s: %w", code, err)
+	}
+	return &promo, nil
+}
+
+// applicable returns the promo code if it can be used on the booking, otherwise a validation error on promoCode.
+// The limits are checked here too for a helpful quote, but only redeem enforces them.
+func (s *PromoService) applicable(ctx context.Context, code string, userId, carparkId, priceGroupId int, start, end time.Time) (*models.PromoCode, error) {
+	promo, err := s.findPromoCode(ctx, code)
+	if errors.Is(err, apperrors.PromoCodeNotFound) {
+		return nil, promoCodeError("Unknown promo code")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	switch {
+	case now.Before(promo.ValidFrom) || !now.Before(promo.ValidTo):
+		return nil, promoCodeError("Promo code is not valid now")
+	case end.Sub(start) < time.Duration(promo.MinBookingMinutes)*time.Minute:
+		return nil, promoCodeError(fmt.Sprintf("Promo code needs a booking of at least %d minutes", promo.MinBookingMinutes))
+	case len(promo.CarparkIds) > 0 && !slices.Contains(promo.CarparkIds, carparkId):
+		return nil, promoCodeError("Promo code does not apply to this carpark")
+	case len(promo.PriceGroupIds) > 0 && !slices.Contains(promo.PriceGroupIds, priceGroupId):
+		return nil, promoCodeError("Promo code does not apply to this vehicle")
+	case promo.MaxRedemptions > 0 && promo.Redemptions >= promo.MaxRedemptions:
+		return nil, fmt.Errorf("promo code %s: %w", promo.Code, apperrors.PromoCodeExhausted)
+	}
+
+	if promo.MaxPerUser > 0 {
+		var redemption models.PromoRedemption
+		err = s.redemptions.FindOne(ctx, bson.M{"_id": redemptionId(promo.Code, userId)}).Decode(&redemption)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("failed to find redemptions of %s: %w", promo.Code, err)
+		}
+		if redemption.Count >= promo.MaxPerUser {
+			return nil, fmt.Errorf("promo code %s for user %d: %w", promo.Code, userId, apperrors.PromoCodeExhausted)
+		}
+	}
+
+	return promo, nil
+}
+
+// redeem counts one use of the code by the user. Both limits are in the update filters,
+// so concurrent redemptions can never go past them.
+func (s *PromoService) redeem(ctx context.Context, promo *models.PromoCode, userId int) error {
+	// 1. Global limit: only increment while below maxRedemptions
+	filter := bson.M{
+		"_id": promo.Code,
+		"$or": bson.A{
+			bson.M{"maxRedemptions": 0},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$redemptions", "$maxRedemptions"}}},
+		},
+	}
+	result, err := s.coll.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"redemptions": 1}})
+	if err != nil {
+		return fmt.Errorf("failed to redeem promo code %s: %w", promo.Code, err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("promo code %s: %w", promo.Code, apperrors.PromoCodeExhausted)
+	}
+
+	giveBack := func() {
+		if _, undoErr := s.coll.UpdateOne(ctx, bson.M{"_id": promo.Code}, bson.M{"$inc": bson.M{"redemptions": -1}}); undoErr != nil {
+			log.Error().Err(undoErr).Str("code", promo.Code).Msg("failed to give back promo code redemption")
+		}
+	}
+
+	// 2. Make sure the user's counter exists. The upsert filters on _id alone, so concurrent first
+	// redemptions all end up on the same document and the server retries the one that loses the insert.
+	userFilter := bson.M{"_id": redemptionId(promo.Code, userId)}
+	create := bson.M{
+		"$setOnInsert": bson.M{"code": promo.Code, "userId": userId, "count": 0},
+	}
+	_, err = s.redemptions.UpdateOne(ctx, userFilter, create, options.UpdateOne().SetUpsert(true))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		giveBack()
+		return fmt.Errorf("failed to redeem promo code %s: %w", promo.Code, err)
+	}
+
+	// 3. Per user limit: only increment while below maxPerUser
+	if promo.MaxPerUser > 0 {
+		userFilter["count"] = bson.M{"$lt": promo.MaxPerUser}
+	}
+	result, err = s.redemptions.UpdateOne(ctx, userFilter, bson.M{"$inc": bson.M{"count": 1}})
+	if err != nil {
+		giveBack()
+		return fmt.Errorf("failed to redeem promo code %s: %w", promo.Code, err)
+	}
+	if result.MatchedCount == 0 {
+		giveBack()
+		return fmt.Errorf("promo code %s for user %d: %w", promo.Code, userId, apperrors.PromoCodeExhausted)
+	}
+	return nil
+}
+
+// release undoes redeem when the booking the code was redeemed for could not be made
+func (s *PromoService) release(ctx context.Context, code string, userId int) error {
+	if _, err := s.coll.UpdateOne(ctx, bson.M{"_id": code}, bson.M{"$inc": bson.M{"redemptions": -1}}); err != nil {
+		return fmt.Errorf("failed to release promo code %s: %w", code, err)
+	}
+
+	filter := bson.M{"_id": redemptionId(code, userId)}
+	if _, err := s.redemptions.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"count": -1}}); err != nil {
+		return fmt.Errorf("failed to release promo code %s for user %d: %w", code, userId, err)
+	}
+	return nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func redemptionId(code string, userId int) string {
+	return code + ":" + strconv.Itoa(userId)
+}
+
+func promoCodeError(message string) error {
+	return &apperrors.ValidationError{Fields: map[string][]string{"promoCode": {message}}}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	apperrors "example/golang-learn/utilities/errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	FrequencyDaily  = "daily"
+	FrequencyWeekly = "weekly"
+
+	// maxOccurrences and maxSeriesDays cap a series so a rule cannot fill the vehicle forever
+	maxOccurrences = 366
+	maxSeriesDays  = 2 * 366
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// expandOccurrences turns the rule into concrete windows. Days are counted in Singapore time
+// so an 8am booking stays at 8am local on every occurrence.
+func expandOccurrences(req dtos.CreateRecurringBookingRequest) ([]dtos.TimeWindow, error) {
+	// 1. Validate the rule
+	fields := make(map[string][]string)
+	if req.Frequency != FrequencyDaily && req.Frequency != FrequencyWeekly {
+		fields["frequency"] = append(fields["frequency"], "Frequency must be daily or weekly")
+	}
+	if req.Interval < 0 {
+		fields["interval"] = append(fields["interval"], "Interval must be positive")
+	}
+	if req.Until == nil && req.Count <= 0 {
+		fields["until"] = append(fields["until"], "Until or count is required")
+	}
+	if req.Count > maxOccurrences {
+		fields["count"] = append(fields["count"], fmt.Sprintf("Count must be at most %d", maxOccurrences))
+	}
+	if !req.End.After(req.Start) {
+		fields["end"] = append(fields["end"], "End must be after start")
+	}
+
+	start := req.Start.In(models.OpeningHoursLocation)
+	if req.Until != nil && req.Until.After(start.AddDate(0, 0, maxSeriesDays)) {
+		fields["until"] = append(fields["until"], fmt.Sprintf("Until must be at most %d days after start", maxSeriesDays))
+	}
+	weekdays := []time.Weekday{}
+	for _, day := range req.ByDay {
+		weekday, ok := rruleWeekdays[strings.ToUpper(day)]
+		if !ok {
+			fields["byDay"] = append(fields["byDay"], fmt.Sprintf("%s is not a weekday, use MO to SU", day))
+			continue
+		}
+		weekdays = append(weekdays, weekday)
+	}
+	if len(fields) > 0 {
+		return nil, &apperrors.ValidationError{Fields: fields}
+	}
+
+	interval := max(req.Interval, 1)
+	if req.Frequency == FrequencyWeekly && len(weekdays) == 0 {
+		weekdays = []time.Weekday{start.Weekday()}
+	}
+
+	// 2. Walk day by day from the first occurrence, weeks start on Monday like RRULE's default WKST
+	duration := req.End.Sub(req.Start)
+	weekStart := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+	var windows []dtos.TimeWindow
+	for day := 0; day <= maxSeriesDays; day++ {
+		occurrence := start.AddDate(0, 0, day)
+		if req.Until != nil && occurrence.After(*req.Until) {
+			break
+		}
+		if req.Count > 0 && len(windows) == req.Count {
+			break
+		}
+
+		switch req.Frequency {
+		case FrequencyDaily:
+			if day%interval != 0 {
+				continue
+			}
+		case FrequencyWeekly:
+			week := int(occurrence.Sub(weekStart).Hours()/24) / 7
+			if week%interval != 0 {
+				continue
+			}
+		}
+		if len(weekdays) > 0 && !slices.Contains(weekdays, occurrence.Weekday()) {
+			continue
+		}
+
+		// count is capped above, only an until far enough away gets here
+		if len(windows) == maxOccurrences {
+			return nil, &apperrors.ValidationError{Fields: map[string][]string{
+				"until": {fmt.Sprintf("Until must leave at most %d occurrences", maxOccurrences)},
+			}}
+		}
+		windows = append(windows, dtos.TimeWindow{
+			Start: occurrence.UTC(),
+			End:   occurrence.Add(duration).UTC(),
+		})
+	}
+
+	return windows, nil
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"errors"
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	apperrors "example/golang-learn/utilities/errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// sgt reads "2006-01-02 15:04" in Singapore time, the zone opening hours and recurrences use
+func sgt(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, models.OpeningHoursLocation)
+	if err != nil {
+		t.Fatalf("bad time %q: %v", value, err)
+	}
+	return parsed
+}
+
+func TestExpandOccurrences(t *testing.T) {
+	// 2026-10-19 is a Monday
+	until := sgt(t, "2026-10-25 08:00")
+	capUntil := sgt(t, "2027-10-19 08:00")
+
+	tests := []struct {
+		name      string
+		req       dtos.CreateRecurringBookingRequest
+		wantCount int
+		wantFirst string
+		wantLast  string
+	}{
+		{
+			name:      "daily by count",
+			req:       dtos.CreateRecurringBookingRequest{Frequency: FrequencyDaily, Count: 3},
+			wantCount: 3,
+			wantFirst: "2026-10-19 08:00",
+			wantLast:  "2026-10-21 08:00",
+		},
+		{
+			name:      "every other day",
+			req:       dtos.CreateRecurringBookingRequest{Frequency: FrequencyDaily, Interval: 2, Count: 3},
+			wantCount: 3,
+			wantFirst: "2026-10-19 08:00",
+			wantLast:  "2026-10-23 08:00",
+		},
+		{
+			name:      "daily until is inclusive",
+			req:       dtos.CreateRecurringBookingRequest{Frequency: FrequencyDaily, Until: &until},
+			wantCount: 7,
+			wantFirst: "2026-10-19 08:00",
+			wantLast:  "2026-10-25 08:00",
+		},
+		{
+			name:      "weekly defaults to the start weekday",
+			req:       dtos.CreateRecurringBookingRequest{Frequency: FrequencyWeekly, Count: 3},
+			wantCount: 3,
+			wantFirst: "2026-10-19 08:00",
+			wantLast:  "2026-11-02 08:00",
+		},
+		{
+			name:      "weekly on several days",
+			req:       dtos.CreateRecurringBookingRequest{Frequency: FrequencyWeekly, ByDay: []string{"mo", "WE", "FR"}, Count: 4},
+			wantCount: 4,
+			wantFirst: "2026-10-19 08:00",
+			wantLast:  "2026-10-26 08:00",
+		},
+		{
+			name:      "every other week",
+			req:       dtos.CreateRecurringBookingRequest{Frequency: FrequencyWeekly, Interval: 2, ByDay: []string{"MO", "TU"}, Count: 4},
+			wantCount: 4,
+			wantFirst: "2026-10-19 08:00",
+			wantLast:  "2026-11-03 08:00",
+		},
+		{
+			name:      "daily until the last occurrence the cap allows",
+			req:       dtos.CreateRecurringBookingRequest{Frequency: FrequencyDaily, Until: &capUntil},
+			wantCount: maxOccurrences,
+			wantFirst: "2026-10-19 08:00",
+			wantLast:  "2027-10-19 08:00",
+		},
+		{
+			name:      "daily limited to sundays",
+			req:       dtos.CreateRecurringBookingRequest{Frequency: FrequencyDaily, ByDay: []string{"SU"}, Until: &until},
+			wantCount: 1,
+			wantFirst: "2026-10-25 08:00",
+			wantLast:  "2026-10-25 08:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Start = sgt(t, "2026-10-19 08:00")
+			tt.req.End = sgt(t, "2026-10-19 10:00")
+
+			windows, err := expandOccurrences(tt.req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(windows) != tt.wantCount {
+				t.Fatalf("got %d occurrences, want %d", len(windows), tt.wantCount)
+			}
+			if !windows[0].Start.Equal(sgt(t, tt.wantFirst)) {
+				t.Errorf("first occurrence %s, want %s", windows[0].Start.In(models.OpeningHoursLocation), tt.wantFirst)
+			}
+			last := windows[len(windows)-1]
+			if !last.Start.Equal(sgt(t, tt.wantLast)) {
+				t.Errorf("last occurrence %s, want %s", last.Start.In(models.OpeningHoursLocation), tt.wantLast)
+			}
+			for _, window := range windows {
+				if window.End.Sub(window.Start) != 2*time.Hour {
+					t.Errorf("occurrence %s lasts %v, want 2h", window.Start, window.End.Sub(window.Start))
+				}
+				if window.Start.In(models.OpeningHoursLocation).Hour() != 8 {
+					t.Errorf("occurrence %s does not start at 8am local", window.Start)
+				}
+			}
+		})
+	}
+}
+
+func TestExpandOccurrencesValidation(t *testing.T) {
+	// more than maxOccurrences daily occurrences but within maxSeriesDays
+	pastOccurrenceCap := sgt(t, "2027-10-20 08:00")
+	pastDayCap := sgt(t, "2030-01-01 00:00")
+
+	tests := []struct {
+		name      string
+		req       dtos.CreateRecurringBookingRequest
+		wantField string
+	}{
+		{"unknown frequency", dtos.CreateRecurringBookingRequest{Frequency: "monthly", Count: 1}, "frequency"},
+		{"negative interval", dtos.CreateRecurringBookingRequest{Frequency: FrequencyDaily, Interval: -1, Count: 1}, "interval"},
+		{"neither until nor count", dtos.CreateRecurringBookingRequest{Frequency: FrequencyDaily}, "until"},
+		{"count above the cap", dtos.CreateRecurringBookingRequest{Frequency: FrequencyDaily, Count: maxOccurrences + 1}, "count"},
+		{"unknown weekday", dtos.CreateRecurringBookingRequest{Frequency: FrequencyWeekly, ByDay: []string{"XX"}, Count: 1}, "byDay"},
+		{"until past the occurrence cap", dtos.CreateRecurringBookingRequest{Frequency: FrequencyDaily, Until: &pastOccurrenceCap}, "until"},
+		{"until past the day cap", dtos.CreateRecurringBookingRequest{Frequency: FrequencyWeekly, Interval: 52, Until: &pastDayCap}, "until"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Start = sgt(t, "2026-10-19 08:00")
+			tt.req.End = sgt(t, "2026-10-19 10:00")
+
+			_, err := expandOccurrences(tt.req)
+			var validation *apperrors.ValidationError
+			if !errors.As(err, &validation) {
+				t.Fatalf("got %v, want a validation error", err)
+			}
+			if _, ok := validation.Fields[tt.wantField]; !ok {
+				t.Errorf("got fields %v, want %s", validation.Fields, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestOccurrenceConflicts(t *testing.T) {
+	now := sgt(t, "2026-10-18 12:00")
+	rules := bookingRules{
+		minDuration: 30 * time.Minute,
+		maxDuration: 24 * time.Hour,
+		granularity: 15 * time.Minute,
+		maxLead:     90 * 24 * time.Hour,
+	}
+	window := func(start, end string) dtos.TimeWindow {
+		return dtos.TimeWindow{Start: sgt(t, start).UTC(), End: sgt(t, end).UTC()}
+	}
+
+	tests := []struct {
+		name        string
+		carpark     models.Carpark
+		schedules   []models.Schedule
+		buffer      time.Duration
+		windows     []dtos.TimeWindow
+		wantReasons []string
+	}{
+		{
+			name:        "free series",
+			windows:     []dtos.TimeWindow{window("2026-10-19 08:00", "2026-10-19 10:00"), window("2026-10-20 08:00", "2026-10-20 10:00")},
+			wantReasons: []string{},
+		},
+		{
+			name:        "breaks the booking rules",
+			windows:     []dtos.TimeWindow{window("2026-10-19 08:05", "2026-10-19 10:00")},
+			wantReasons: []string{"Start must be aligned to 15m0s"},
+		},
+		{
+			name: "outside opening hours",
+			carpark: models.Carpark{OpeningHours: &models.OpeningTimes{Weekly: []models.OpeningHours{
+				{Weekday: time.Monday, Open: "09:00", Close: "18:00"},
+				{Weekday: time.Tuesday, Open: "07:00", Close: "18:00"},
+			}}},
+			windows:     []dtos.TimeWindow{window("2026-10-19 08:00", "2026-10-19 10:00"), window("2026-10-20 08:00", "2026-10-20 10:00")},
+			wantReasons: []string{"outside opening hours"},
+		},
+		{
+			name: "carpark closed",
+			carpark: models.Carpark{Closures: []models.Closure{
+				{Id: 1, Start: sgt(t, "2026-10-20 00:00"), End: sgt(t, "2026-10-21 00:00")},
+			}},
+			windows:     []dtos.TimeWindow{window("2026-10-19 08:00", "2026-10-19 10:00"), window("2026-10-20 08:00", "2026-10-20 10:00")},
+			wantReasons: []string{apperrors.CarparkClosed.Error()},
+		},
+		{
+			name: "booked within the buffer",
+			schedules: []models.Schedule{
+				{Type: models.ScheduleTypeBooking, BookingId: 7, Status: models.BookingStatusReserved, Start: sgt(t, "2026-10-19 10:15"), End: sgt(t, "2026-10-19 12:00")},
+			},
+			buffer:      30 * time.Minute,
+			windows:     []dtos.TimeWindow{window("2026-10-19 08:00", "2026-10-19 10:00")},
+			wantReasons: []string{(&apperrors.ScheduleConflictError{BookingId: 7}).Error()},
+		},
+		{
+			name: "cancelled bookings do not conflict",
+			schedules: []models.Schedule{
+				{Type: models.ScheduleTypeBooking, BookingId: 7, Status: models.BookingStatusCancelled, Start: sgt(t, "2026-10-19 08:00"), End: sgt(t, "2026-10-19 10:00")},
+			},
+			windows:     []dtos.TimeWindow{window("2026-10-19 08:00", "2026-10-19 10:00")},
+			wantReasons: []string{},
+		},
+		{
+			name:        "occurrences run into each other",
+			windows:     []dtos.TimeWindow{window("2026-10-19 08:00", "2026-10-19 10:00"), window("2026-10-19 09:30", "2026-10-19 11:00")},
+			wantReasons: []string{"overlaps the previous occurrence"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vehicle := models.Vehicle{Id: 1, Schedules: tt.schedules}
+			conflicts := occurrenceConflicts(&tt.carpark, &vehicle, tt.buffer, rules, tt.windows, now)
+
+			reasons := []string{}
+			for _, conflict := range conflicts {
+				reasons = append(reasons, conflict.Reason)
+			}
+			if !slices.Equal(reasons, tt.wantReasons) {
+				t.Errorf("got conflicts %q, want %q", reasons, tt.wantReasons)
+			}
+		})
+	}
+}
@@ -3,13 +3,36 @@ package services
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+// Setting keys, all stored on the single settings document
+const (
+	SettingRadiusKm    = "RadiusKm"
+	SettingMaxRadiusKm = "MaxRadiusKm"
+	// SettingExpandMaxRadiusKm is the ceiling for searches with expand turned on
+	SettingExpandMaxRadiusKm = "ExpandMaxRadiusKm"
+	// SettingBufferMinutes is the turnaround time kept free between consecutive bookings of a vehicle
+	SettingBufferMinutes = "BufferMinutes"
+	// SettingPriceGroupBufferMinutes overrides the buffer per price group, e.g. {"1": 30}
+	SettingPriceGroupBufferMinutes = "PriceGroupBufferMinutes"
+	// Booking window rules, see bookingRules
+	SettingMinBookingMinutes         = "MinBookingMinutes"
+	SettingMaxBookingMinutes         = "MaxBookingMinutes"
+	SettingBookingGranularityMinutes = "BookingGranularityMinutes"
+	SettingMaxLeadDays               = "MaxLeadDays"
+	// SettingHoldMinutes is how long a checkout hold keeps a vehicle before it expires
+	SettingHoldMinutes = "HoldMinutes"
+)
+
 type SettingService struct {
 	coll *mongo.Collection
 }
@@ -33,6 +56,9 @@ func (s *SettingService) GetString(key string, defaultVal string) (string, error
 func (s *SettingService) GetInt(key string, defaultVal int) (int, error) {
 	var result map[string]any
 	err := s.coll.FindOne(context.Background(), bson.M{}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return defaultVal, nil
+	}
 	if err != nil {
 		return defaultVal, fmt.Errorf("database error: %w", err)
 	}
@@ -42,16 +68,51 @@ func (s *SettingService) GetInt(key string, defaultVal int) (int, error) {
 		return defaultVal, nil
 	}
 
-	// try to assert as int64 (MongoDB default for numbers)
-	if i64, ok := val.(int64); ok {
-		return int(i64), nil
+	// numbers come back as int64, int32 (set from Go or older drivers) or double (set from mongosh),
+	// older deployments still hold some settings as strings such as RadiusKm "20"
+	switch v := val.(type) {
+	case int64:
+		return int(v), nil
+	case int32:
+		return int(v), nil
+	case int:
+		return v, nil
+	case float64:
+		if v == math.Trunc(v) {
+			return int(v), nil
+		}
+	case string:
+		if i, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return i, nil
+		}
 	}
 
-	if i, ok := val.(int); ok {
-		return i, nil
+	// an unusable value should not take down the features reading it, fall back to the default
+	log.Warn().Str("key", key).Interface("value", val).Int("default", defaultVal).Msg("setting is not an integer, using the default")
+	return defaultVal, nil
+}
+
+// Get decodes the value of key into out, for settings stored as documents or arrays.
+// It reports false when the key is not set, leaving out untouched.
+func (s *SettingService) Get(key string, out any) (bool, error) {
+	var result bson.Raw
+	err := s.coll.FindOne(context.Background(), bson.M{}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+
+	val, err := result.LookupErr(key)
+	if err != nil {
+		return false, nil
 	}
 
-	return defaultVal, fmt.Errorf("key '%s' is type %T, not an integer", key, val)
+	if err = val.Unmarshal(out); err != nil {
+		return false, fmt.Errorf("key '%s' could not be decoded: %w", key, err)
+	}
+	return true, nil
 }
 
 func (s *SettingService) Set(key string, value any) error {
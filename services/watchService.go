@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"example/golang-learn/dtos"
+	"example/golang-learn/models"
+	"example/golang-learn/utilities/db"
+	apperrors "example/golang-learn/utilities/errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// WatchService keeps the waitlist, watches are checked again whenever a carpark frees up a vehicle
+type WatchService struct {
+	coll           *mongo.Collection
+	counters       *mongo.Collection
+	carparkService *CarparkService
+	notifier       Notifier
+}
+
+func NewWatchService(coll *mongo.Collection, counters *mongo.Collection, carparkService *CarparkService, notifier Notifier) *WatchService {
+	s := &WatchService{
+		coll:           coll,
+		counters:       counters,
+		carparkService: carparkService,
+		notifier:       notifier,
+	}
+	carparkService.AddAvailabilityListener(s.EvaluateCarpark)
+	return s
+}
+
+func (s *WatchService) CreateWatch(req dtos.CreateWatchRequest) (*models.Watch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 1. Validate
+	fields := make(map[string][]string)
+	if req.CarparkId == 0 && req.RadiusKm <= 0 {
+		fields["carparkId"] = append(fields["carparkId"], "CarparkId or a location with radiusKm is required")
+	}
+	if !req.End.After(req.Start) {
+		fields["end"] = append(fields["end"], "End must be after start")
+	}
+	if len(fields) > 0 {
+		return nil, &apperrors.ValidationError{Fields: fields}
+	}
+
+	// 2. Generate the watch id
+	watchId, err := db.NextSequence(ctx, s.counters, "watches")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate watch id: %w", err)
+	}
+
+	watch := models.Watch{
+		Id:             watchId,
+		UserId:         req.UserId,
+		CarparkId:      req.CarparkId,
+		Longitude:      req.Longitude,
+		Latitude:       req.Latitude,
+		RadiusKm:       req.RadiusKm,
+		Start:          req.Start.UTC(),
+		End:            req.End.UTC(),
+		PriceGroupIds:  req.PriceGroupIds,
+		VehicleTypeIds: req.VehicleTypeIds,
+		NumSeats:       req.NumSeats,
+		Status:         models.WatchStatusActive,
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	if _, err = s.coll.InsertOne(ctx, watch); err != nil {
+		return nil, fmt.Errorf("failed to save watch: %w", err)
+	}
+
+	return &watch, nil
+}
+
+func (s *WatchService) GetWatch(watchId int) (*models.Watch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var watch models.Watch
+	err := s.coll.FindOne(ctx, bson.M{"_id": watchId}).Decode(&watch)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("watch %d: %w", watchId, apperrors.WatchNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find watch %d: %w", watchId, err)
+	}
+
+	return &watch, nil
+}
+
+func (s *WatchService) DeleteWatch(watchId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.coll.DeleteOne(ctx, bson.M{"_id": watchId})
+	if err != nil {
+		return fmt.Errorf("failed to delete watch %d: %w", watchId, err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("watch %d: %w", watchId, apperrors.WatchNotFound)
+	}
+
+	return nil
+}
+
+// EvaluateCarpark notifies every active watch covering the carpark that now has a free matching vehicle.
+// It runs as an availability listener so errors are logged rather than returned.
+func (s *WatchService) EvaluateCarpark(carparkId int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.evaluateCarpark(ctx, carparkId); err != nil {
+		log.Error().Err(err).Int("carparkId", carparkId).Msg("failed to evaluate watches")
+	}
+}
+
+func (s *WatchService) evaluateCarpark(ctx context.Context, carparkId int) error {
+	carpark, err := s.carparkService.findCarparkById(ctx, carparkId)
+	if err != nil {
+		return err
+	}
+
+	// 1. Active watches for this carpark or for a radius, windows that already started are no use any more
+	filter := bson.M{
+		"status": models.WatchStatusActive,
+		"start":  bson.M{"$gt": time.Now().UTC()},
+		"$or": bson.A{
+			bson.M{"carparkId": carparkId},
+			bson.M{"radiusKm": bson.M{"$gt": 0}},
+		},
+	}
+	cursor, err := s.coll.Find(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to find watches: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var watches []models.Watch
+	if err := cursor.All(ctx, &watches); err != nil {
+		return fmt.Errorf("decoding failed: %w", err)
+	}
+
+	buffers, err := s.carparkService.loadBufferRules()
+	if err != nil {
+		return err
+	}
+
+	for _, watch := range watches {
+		// 2. Radius watches only care about carparks inside their circle
+		if watch.CarparkId == 0 && len(carpark.Location.Coordinates) == 2 {
+			distance := distanceKm(watch.Longitude, watch.Latitude, carpark.Location.Coordinates[0], carpark.Location.Coordinates[1])
+			if distance > watch.RadiusKm {
+				continue
+			}
+		} else if watch.CarparkId != carparkId {
+			continue
+		}
+
+		// 3. Count the matching vehicles that are free for the window
+		available := 0
+		for i := range carpark.Vehicles {
+			vehicle := &carpark.Vehicles[i]
+			if vehicleMatches(vehicle, watch.PriceGroupIds, watch.VehicleTypeIds, watch.NumSeats) &&
+				vehicleIsFree(carpark, vehicle, buffers.forVehicle(carpark, vehicle), watch.Start, watch.End) {
+				available++
+			}
+		}
+		if available == 0 {
+			continue
+		}
+
+		if err := s.notify(ctx, watch, carpark, available); err != nil {
+			log.Error().Err(err).Int("watchId", watch.Id).Msg("failed to notify watch")
+		}
+	}
+
+	return nil
+}
+
+// notify marks the watch as notified first so concurrent evaluations send it only once,
+// a failed send makes it active again so the next availability change retries it
+func (s *WatchService) notify(ctx context.Context, watch models.Watch, carpark *models.Carpark, available int) error {
+	// mongo keeps milliseconds, the revert below matches on this exact value
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	filter := bson.M{"_id": watch.Id, "status": models.WatchStatusActive}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     models.WatchStatusNotified,
+			"notifiedAt": now,
+		},
+	}
+
+	result, err := s.coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update watch %d: %w", watch.Id, err)
+	}
+	if result.ModifiedCount == 0 {
+		return nil
+	}
+
+	err = s.notifier.Notify(ctx, models.Notification{
+		WatchId:           watch.Id,
+		UserId:            watch.UserId,
+		CarparkId:         carpark.Id,
+		CarparkName:       carpark.Name,
+		Start:             watch.Start,
+		End:               watch.End,
+		AvailableVehicles: available,
+		CreatedAt:         now,
+	})
+	if err == nil {
+		return nil
+	}
+
+	// only undo our own claim, the watch may have been deleted in the meantime
+	revertFilter := bson.M{"_id": watch.Id, "status": models.WatchStatusNotified, "notifiedAt": now}
+	revert := bson.M{
+		"$set":   bson.M{"status": models.WatchStatusActive},
+		"$unset": bson.M{"notifiedAt": ""},
+	}
+	if _, revertErr := s.coll.UpdateOne(ctx, revertFilter, revert); revertErr != nil {
+		log.Error().Err(revertErr).Int("watchId", watch.Id).Msg("failed to reactivate watch after a failed notification")
+	}
+	return fmt.Errorf("failed to send notification of watch %d: %w", watch.Id, err)
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// NextSequence atomically increments the named counter and returns the new value,
+// so concurrent callers never get the same id
+func NextSequence(ctx context.Context, counters *mongo.Collection, name string) (int, error) {
+	return NextSequenceRange(ctx, counters, name, 1)
+}
+
+// NextSequenceRange reserves n consecutive values of the named counter and returns the first one
+func NextSequenceRange(ctx context.Context, counters *mongo.Collection, name string, n int) (int, error) {
+	filter := bson.M{"_id": name}
+	update := bson.M{"$inc": bson.M{"seq": n}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	err := counters.FindOneAndUpdate(ctx, filter, update, opts).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+
+	return counter.Seq - n + 1, nil
+}
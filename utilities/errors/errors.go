@@ -1,6 +1,64 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
 
 var CarparkNotFound = errors.New("carpark not found")
+var VehicleNotFound = errors.New("vehicle not found")
+var BookingNotFound = errors.New("booking not found")
+var BlockNotFound = errors.New("block not found")
+var ClosureNotFound = errors.New("closure not found")
+var ScheduleNotFound = errors.New("schedule not found")
+var CarparkClosed = errors.New("carpark is closed")
+var WatchNotFound = errors.New("watch not found")
+var PriceGroupNotFound = errors.New("price group not found")
+var PriceGroupInUse = errors.New("price group still has vehicles")
+var DiscountNotFound = errors.New("discount not found")
+var DiscountOverlap = errors.New("discount overlaps another discount of the vehicle")
+var PromoCodeNotFound = errors.New("promo code not found")
+var PromoCodeExhausted = errors.New("promo code has no redemptions left")
+var InvalidStatusTransition = errors.New("invalid booking status transition")
+var BookingClosed = errors.New("booking is already closed")
+var HoldExpired = errors.New("hold has expired")
+var NoVehicleAvailable = errors.New("no matching vehicle is available")
 var ErrInvalidPassword = errors.New("invalid credentials")
+
+// ScheduleConflictError is returned when a new schedule overlaps an existing one on the same vehicle
+type ScheduleConflictError struct {
+	BookingId int
+	BlockId   int
+}
+
+func (e *ScheduleConflictError) Error() string {
+	switch {
+	case e.BookingId != 0:
+		return fmt.Sprintf("schedule overlaps booking %d", e.BookingId)
+	case e.BlockId != 0:
+		return fmt.Sprintf("schedule overlaps block %d", e.BlockId)
+	default:
+		return "schedule overlaps an existing booking"
+	}
+}
+
+// ValidationError holds the messages of every field that failed validation
+type ValidationError struct {
+	Fields map[string][]string
+}
+
+func (e *ValidationError) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for key := range e.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var messages []string
+	for _, key := range keys {
+		messages = append(messages, e.Fields[key]...)
+	}
+	return strings.Join(messages, "; ")
+}